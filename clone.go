@@ -0,0 +1,43 @@
+// clone.go - Cloning scanners.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns a copy of the [*Scanner] that does not share slices and maps
+// with the original, which allows tweaking the configuration per invocation
+// (e.g., setting [Scanner.DisableOptionParsing]) without affecting a shared
+// [*Scanner]. The copy shares the functions and the [Scanner.Interner].
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) Clone() *Scanner {
+	clone := *sx
+	clone.Prefixes = slices.Clone(sx.Prefixes)
+	clone.PrefixSwitchOptions = maps.Clone(sx.PrefixSwitchOptions)
+	for name, prefixes := range clone.PrefixSwitchOptions {
+		clone.PrefixSwitchOptions[name] = slices.Clone(prefixes)
+	}
+	clone.AfterSeparatorEscapes = slices.Clone(sx.AfterSeparatorEscapes)
+	clone.LongOptionsWithAttachedValue = maps.Clone(sx.LongOptionsWithAttachedValue)
+	clone.ValueSeparators = slices.Clone(sx.ValueSeparators)
+	clone.ValueListDelimiter = maps.Clone(sx.ValueListDelimiter)
+	clone.OptionsWithArity = maps.Clone(sx.OptionsWithArity)
+	clone.OptionEnums = maps.Clone(sx.OptionEnums)
+	for name, allowed := range clone.OptionEnums {
+		clone.OptionEnums[name] = slices.Clone(allowed)
+	}
+	clone.OptionMaxOccurrences = maps.Clone(sx.OptionMaxOccurrences)
+	clone.PositionalNames = slices.Clone(sx.PositionalNames)
+	clone.PrefixRequiresNonDigit = maps.Clone(sx.PrefixRequiresNonDigit)
+	clone.TerminatingOptions = maps.Clone(sx.TerminatingOptions)
+	clone.CountableFlags = maps.Clone(sx.CountableFlags)
+	clone.KnownSubcommands = maps.Clone(sx.KnownSubcommands)
+	clone.XStylePrefixes = maps.Clone(sx.XStylePrefixes)
+	clone.DetectPrefixes = slices.Clone(sx.DetectPrefixes)
+	clone.DeprecatedOptions = maps.Clone(sx.DeprecatedOptions)
+	return &clone
+}
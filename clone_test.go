@@ -0,0 +1,58 @@
+// clone_test.go - Tests for cloning scanners.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [*Scanner.Clone] copies every slice and
+// map, so that modifying the clone does not affect the original.
+func TestScannerClone(t *testing.T) {
+	original := &Scanner{
+		Prefixes:                     []string{"-", "--"},
+		Separator:                    "--",
+		PrefixSwitchOptions:          map[string][]string{"legacy": {"-"}},
+		AfterSeparatorEscapes:        []string{"--meta"},
+		LongOptionsWithAttachedValue: map[string]bool{"port": true},
+		ValueSeparators:              []string{"="},
+		ValueListDelimiter:           map[string]string{"tags": ","},
+		OptionsWithArity:             map[string]int{"file": 1},
+		OptionEnums:                  map[string][]string{"color": {"auto"}},
+		OptionMaxOccurrences:         map[string]int{"config": 1},
+		PositionalNames:              []string{"input"},
+		PrefixRequiresNonDigit:       map[string]bool{"-": true},
+		TerminatingOptions:           map[string]bool{"e": true},
+		CountableFlags:               map[string]bool{"v": true},
+		KnownSubcommands:             map[string]bool{"run": true},
+		XStylePrefixes:               map[string]bool{"-": true},
+		DetectPrefixes:               []string{"%"},
+		DeprecatedOptions:            map[string]string{"old": "use --new"},
+	}
+	clone := original.Clone()
+
+	ov, cv := reflect.ValueOf(original).Elem(), reflect.ValueOf(clone).Elem()
+	for idx := 0; idx < ov.NumField(); idx++ {
+		field := ov.Type().Field(idx)
+		switch field.Type.Kind() {
+		case reflect.Slice, reflect.Map:
+			if ov.Field(idx).IsNil() {
+				t.Fatalf("field %s is not set by this test", field.Name)
+			}
+			if ov.Field(idx).Pointer() == cv.Field(idx).Pointer() {
+				t.Errorf("Clone() shares the %s field", field.Name)
+			}
+		}
+	}
+	if !reflect.DeepEqual(original, clone) {
+		t.Errorf("Clone() = %#v, want %#v", clone, original)
+	}
+
+	clone.DisableOptionParsing = true
+	clone.OptionEnums["color"][0] = "never"
+	if original.DisableOptionParsing || original.OptionEnums["color"][0] != "auto" {
+		t.Errorf("modifying the clone modified the original: %#v", original)
+	}
+}
@@ -0,0 +1,113 @@
+// compare.go - Helpers for comparing token streams.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"hash/fnv"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// TokensEqualIgnoreIndex returns whether a and b contain the same tokens
+// in the same order, comparing their types and all their fields except
+// the Idx field containing the position in the command line arguments.
+//
+// This is useful to test transformations that renumber tokens.
+func TokensEqualIgnoreIndex(a, b []Token) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if !reflect.DeepEqual(withoutIndex(a[idx]), withoutIndex(b[idx])) {
+			return false
+		}
+	}
+	return true
+}
+
+// withoutIndex returns a copy of the token with a zero Idx field.
+func withoutIndex(token Token) any {
+	value := reflect.ValueOf(token)
+	if value.Kind() != reflect.Struct {
+		return token
+	}
+	clone := reflect.New(value.Type()).Elem()
+	clone.Set(value)
+	if field := clone.FieldByName("Idx"); field.IsValid() && field.CanSet() && field.Kind() == reflect.Int {
+		field.SetInt(0)
+	}
+	return clone.Interface()
+}
+
+// CanonicalHash returns a hash of the tokens insensitive to the command-line
+// style but sensitive to the semantics, suitable as a caching key. We hash the
+// following normalized representation of each token, in order:
+//
+//  1. options use the [OptionToken.GNUForm], along with their DeclaredType,
+//     whether they are negated, their count, and their ValueList, therefore "--file=x",
+//     "--file x", and the Go-style "-file=x" hash equally;
+//
+//  2. the separator is hashed regardless of its spelling;
+//
+//  3. the other tokens use their String, except that the [RawRemainderToken]
+//     hashes each argument as a positional argument and we ignore the [EndToken].
+//
+// The hash only depends on such representation, therefore it is stable across
+// runs, but it may change with future versions of this package.
+func CanonicalHash(tokens []Token) uint64 {
+	hasher := fnv.New64a()
+	for _, record := range canonicalRecords(tokens) {
+		hasher.Write([]byte(record[0]))
+		for _, field := range record[1:] {
+			hasher.Write([]byte{0})
+			hasher.Write([]byte(field))
+		}
+		hasher.Write([]byte{1})
+	}
+	return hasher.Sum64()
+}
+
+// SameSemantics returns whether a and b have the same semantics regardless
+// of the command-line style, which is useful to verify that migrating from a
+// style to another is lossless. We compare the same normalized representation
+// used by [CanonicalHash], therefore prefixes, value separators, and whether
+// values are attached or consumed do not matter, while the options, their
+// values, and the positional arguments, in order, must match.
+func SameSemantics(a, b []Token) bool {
+	return slices.EqualFunc(canonicalRecords(a), canonicalRecords(b), slices.Equal)
+}
+
+// canonicalRecords returns the normalized representation of the tokens used
+// by [CanonicalHash] and [SameSemantics], where each record contains the kind
+// of the token followed by its normalized fields.
+func canonicalRecords(tokens []Token) [][]string {
+	var records [][]string
+	write := func(kind string, fields ...string) {
+		records = append(records, append([]string{kind}, fields...))
+	}
+	for _, token := range tokens {
+		switch tk := token.(type) {
+		case OptionToken:
+			fields := []string{tk.GNUForm(), tk.DeclaredType, strconv.FormatBool(tk.Negated), strconv.Itoa(tk.Count)}
+			if tk.Consumed != 1 {
+				fields = append(fields, tk.ValueList...)
+			}
+			write("option", fields...)
+		case OptionsArgumentsSeparatorToken:
+			write("separator")
+		case RawRemainderToken:
+			for _, arg := range tk.Args {
+				write("positional", arg)
+			}
+		case PositionalArgumentToken:
+			write("positional", tk.Value)
+		case EndToken:
+			// nothing
+		default:
+			write(reflect.TypeOf(token).Name(), tk.String())
+		}
+	}
+	return records
+}
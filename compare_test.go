@@ -0,0 +1,201 @@
+// compare_test.go - Tests for comparing token streams.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import "testing"
+
+// This test ensures that [TokensEqualIgnoreIndex] ignores index changes
+// while still detecting type and value changes.
+func TestTokensEqualIgnoreIndex(t *testing.T) {
+	base := []Token{
+		OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+		PositionalArgumentToken{Idx: 1, Value: "file.txt"},
+		OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+	}
+
+	tests := []struct {
+		name     string
+		other    []Token
+		expected bool
+	}{
+		{
+			name:     "identical",
+			other:    base,
+			expected: true,
+		},
+		{
+			name: "renumbered",
+			other: []Token{
+				OptionToken{Idx: 4, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 5, Value: "file.txt"},
+				OptionsArgumentsSeparatorToken{Idx: 6, Separator: "--"},
+			},
+			expected: true,
+		},
+		{
+			name: "type change",
+			other: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "file.txt"},
+				PositionalArgumentToken{Idx: 2, Value: "--"},
+			},
+			expected: false,
+		},
+		{
+			name: "value change",
+			other: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "file.txt"},
+				OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+			},
+			expected: false,
+		},
+		{
+			name:     "length change",
+			other:    base[:2],
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TokensEqualIgnoreIndex(base, tt.other)
+			if got != tt.expected {
+				t.Errorf("TokensEqualIgnoreIndex() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [CanonicalHash] is insensitive to the
+// command-line style but sensitive to the semantics.
+func TestCanonicalHash(t *testing.T) {
+	gnu := &Scanner{
+		Prefixes:         []string{"-", "--"},
+		Separator:        "--",
+		SplitValues:      true,
+		OptionsWithArity: map[string]int{"file": 1},
+	}
+	goStyle := &Scanner{
+		Prefixes:    []string{"-"},
+		Separator:   "--",
+		SplitValues: true,
+	}
+	hash := func(sx *Scanner, args ...string) uint64 {
+		return CanonicalHash(sx.Scan(args))
+	}
+
+	t.Run("equivalent streams", func(t *testing.T) {
+		expect := hash(gnu, "--file=x", "-v", "a.txt", "--", "b")
+		for _, got := range []uint64{
+			hash(gnu, "--file", "x", "-v", "a.txt", "--", "b"),
+			hash(goStyle, "-file=x", "-v", "a.txt", "--", "b"),
+			hash(&Scanner{Prefixes: []string{"-", "--"}, Separator: "--", SplitValues: true, EmitEndToken: true},
+				"--file=x", "--v", "a.txt", "--", "b"),
+		} {
+			if got != expect {
+				t.Errorf("CanonicalHash() = %#x, want %#x", got, expect)
+			}
+		}
+	})
+
+	t.Run("different streams", func(t *testing.T) {
+		hashes := []uint64{
+			hash(gnu, "--file=x", "-v"),
+			hash(gnu, "--file=y", "-v"),
+			hash(gnu, "-v", "--file=x"),
+			hash(gnu, "--file=x", "v"),
+			hash(gnu, "--file=x", "--", "-v"),
+			hash(gnu, "--file=x"),
+		}
+		seen := make(map[uint64]int)
+		for idx, value := range hashes {
+			if prev, found := seen[value]; found {
+				t.Errorf("CanonicalHash() of streams %d and %d collide", prev, idx)
+			}
+			seen[value] = idx
+		}
+	})
+}
+
+// This test ensures that [SameSemantics] ignores the command-line
+// style while detecting differences in options, values, and positionals.
+func TestSameSemantics(t *testing.T) {
+	gnu := &Scanner{
+		Prefixes:         []string{"-", "--"},
+		Separator:        "--",
+		SplitValues:      true,
+		OptionsWithArity: map[string]int{"file": 1},
+	}
+	dig := &Scanner{
+		Prefixes:    []string{"-", "--", "+"},
+		Separator:   "--",
+		SplitValues: true,
+	}
+	windows := NewWindowsScanner()
+	typed := &Scanner{
+		Prefixes:              []string{"--"},
+		SplitValues:           true,
+		OptionsWithArity:      map[string]int{"count": 1},
+		InlineTypeAnnotations: true,
+	}
+
+	tests := []struct {
+		name     string
+		a        []Token
+		b        []Token
+		expected bool
+	}{
+		{
+			name:     "GNU and dig styles",
+			a:        gnu.Scan([]string{"--file", "x", "-v", "a.txt"}),
+			b:        dig.Scan([]string{"+file=x", "+v", "a.txt"}),
+			expected: true,
+		},
+		{
+			name:     "GNU and Windows styles",
+			a:        gnu.Scan([]string{"--file=x", "-v", "a.txt"}),
+			b:        windows.Scan([]string{"/file:x", "/v", "a.txt"}),
+			expected: true,
+		},
+		{
+			name:     "different values",
+			a:        gnu.Scan([]string{"--file", "x", "-v"}),
+			b:        dig.Scan([]string{"+file=y", "+v"}),
+			expected: false,
+		},
+		{
+			name:     "different positionals",
+			a:        gnu.Scan([]string{"-v", "a.txt"}),
+			b:        windows.Scan([]string{"/v", "b.txt"}),
+			expected: false,
+		},
+		{
+			name:     "different lengths",
+			a:        gnu.Scan([]string{"-v", "a.txt"}),
+			b:        gnu.Scan([]string{"-v"}),
+			expected: false,
+		},
+		{
+			name:     "different declared types",
+			a:        typed.Scan([]string{"--count:int=5"}),
+			b:        typed.Scan([]string{"--count:string=5"}),
+			expected: false,
+		},
+		{
+			name:     "same declared types",
+			a:        typed.Scan([]string{"--count:int=5"}),
+			b:        typed.Scan([]string{"--count:int", "5"}),
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameSemantics(tt.a, tt.b); got != tt.expected {
+				t.Errorf("SameSemantics(%#v, %#v) = %v, want %v", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
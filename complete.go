@@ -0,0 +1,77 @@
+// complete.go - Completing partially-typed options.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"slices"
+	"strings"
+)
+
+// CompleteOptions returns the options, including the prefix, completing the
+// partially-typed final argument, for implementing shell completion. For example,
+// when the final argument is "--ver" and known contains "verbose" and "version",
+// the result is "--verbose" and "--version". When the final argument is a bare
+// prefix (e.g., "--"), we complete all the known options using such prefix.
+//
+// We return nil when the final argument is a positional argument or when
+// it is the value of an option (e.g., when using [Scanner.SplitValues]) or
+// a bundle of short options (see [Scanner.BundleShortOptions]), as well as
+// when using [Scanner.PositionalOnly].
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) CompleteOptions(args []string, known []string) []string {
+	if len(args) == 0 || sx.PositionalOnly {
+		return nil
+	}
+	last := len(args) - 1
+	tokens := sx.Scan(args)
+	if count := len(tokens); count > 0 {
+		if _, ok := tokens[count-1].(EndToken); ok {
+			tokens = tokens[:count-1]
+		}
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	var prefix, partial string
+	switch tk := tokens[len(tokens)-1].(type) {
+	case OptionToken:
+		if tk.Idx != last || tk.SubIdx > 0 || tk.FromBundle || tk.hasValue() || tk.Consumed > 0 {
+			return nil
+		}
+		prefix, partial = tk.Prefix, tk.Name
+
+	case OptionsArgumentsSeparatorToken:
+		if tk.Idx != last || !slices.Contains(sx.Prefixes, tk.Separator) {
+			return nil
+		}
+		prefix = tk.Separator
+
+	case PositionalArgumentToken:
+		if tk.Idx != last || tk.String() != args[last] || !slices.Contains(sx.Prefixes, args[last]) || afterSeparator(tokens) {
+			return nil
+		}
+		prefix = args[last]
+
+	default:
+		return nil
+	}
+
+	var candidates []string
+	for _, name := range known {
+		if strings.HasPrefix(name, partial) {
+			candidates = append(candidates, prefix+name)
+		}
+	}
+	return candidates
+}
+
+// afterSeparator returns whether the tokens contain the separator.
+func afterSeparator(tokens []Token) bool {
+	return slices.ContainsFunc(tokens, func(token Token) bool {
+		_, ok := token.(OptionsArgumentsSeparatorToken)
+		return ok
+	})
+}
@@ -0,0 +1,137 @@
+// complete_test.go - Tests for completing partially-typed options.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [*Scanner.CompleteOptions] completes
+// the partially-typed final option.
+func TestScannerCompleteOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "partial long option",
+			args:     []string{"-x", "--ver"},
+			expected: []string{"--verbose", "--version"},
+		},
+		{
+			name:     "partial short option",
+			args:     []string{"-f"},
+			expected: []string{"-file"},
+		},
+		{
+			name:     "final positional",
+			args:     []string{"--verbose", "ver"},
+			expected: nil,
+		},
+		{
+			name:     "final bare prefix",
+			args:     []string{"+"},
+			expected: []string{"+verbose", "+version", "+file"},
+		},
+		{
+			name:     "final bare prefix which is the separator",
+			args:     []string{"-x", "--"},
+			expected: []string{"--verbose", "--version", "--file"},
+		},
+		{
+			name:     "final bare prefix after the separator",
+			args:     []string{"--", "+"},
+			expected: nil,
+		},
+		{
+			name:     "final option after the separator",
+			args:     []string{"--", "--ver"},
+			expected: nil,
+		},
+		{
+			name:     "final option value",
+			args:     []string{"--file=ver"},
+			expected: nil,
+		},
+		{
+			name:     "no arguments",
+			args:     nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:    []string{"-", "--", "+"},
+				Separator:   "--",
+				SplitValues: true,
+			}
+			got := scanner.CompleteOptions(tt.args, []string{"verbose", "version", "file"})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("CompleteOptions(%q) = %q, want %q", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [*Scanner.CompleteOptions] ignores the
+// [EndToken] and handles skipped empty arguments.
+func TestScannerCompleteOptionsEdgeCases(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:           []string{"-"},
+		EmitEndToken:       true,
+		SkipEmptyArguments: true,
+	}
+	known := []string{"verbose"}
+
+	if got, expect := scanner.CompleteOptions([]string{"-v"}, known), []string{"-verbose"}; !reflect.DeepEqual(got, expect) {
+		t.Errorf("CompleteOptions() = %q, want %q", got, expect)
+	}
+	if got := scanner.CompleteOptions([]string{""}, known); got != nil {
+		t.Errorf("CompleteOptions() = %q, want nil", got)
+	}
+}
+
+// This test ensures that [*Scanner.CompleteOptions] does not complete
+// the last option of a bundle of short options.
+func TestScannerCompleteOptionsBundle(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "single short option",
+			args:     []string{"-v"},
+			expected: []string{"-verbose", "-version"},
+		},
+		{
+			name:     "bundle",
+			args:     []string{"-av"},
+			expected: nil,
+		},
+		{
+			name:     "counted bundle",
+			args:     []string{"-vv"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:           []string{"-", "--"},
+				BundleShortOptions: true,
+				CountableFlags:     map[string]bool{"v": true},
+			}
+			got := scanner.CompleteOptions(tt.args, []string{"verbose", "version"})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("CompleteOptions(%q) = %q, want %q", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
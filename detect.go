@@ -0,0 +1,63 @@
+// detect.go - Detecting the command line style.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import "strings"
+
+// DetectStyle guesses the command line style of args and returns its name and
+// a new [*Scanner] configured for it, which is useful to tools analyzing arbitrary
+// command lines. We only consider the arguments preceding the first "--" and
+// use the following heuristics, in order:
+//
+//  1. "dig" when any argument starts with "+" followed by a letter (e.g.,
+//     "+trace"), using the "-", "--", and "+" prefixes;
+//
+//  2. "windows" when no argument starts with "-" and any argument starts with
+//     "/" followed by a name not containing "/" (e.g., "/out:file.exe" but
+//     not "/etc/passwd"), using [NewWindowsScanner];
+//
+//  3. "go" when no argument starts with "--" and any argument starts with "-"
+//     followed by a multi-character name (e.g., "-verbose"), using the "-" prefix;
+//
+//  4. "gnu" otherwise, using the "-" and "--" prefixes.
+//
+// The "dig", "go", and "gnu" styles use "--" as the separator. These heuristics
+// cannot distinguish a bundle of short options (e.g., "-vf") from a Go option with
+// a multi-character name, and command lines without options are always "gnu".
+func DetectStyle(args []string) (styleName string, scanner *Scanner) {
+	var dig, dash, doubleDash, multiChar, slash bool
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		switch {
+		case strings.HasPrefix(arg, "--") && len(arg) > 2:
+			dash, doubleDash = true, true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			name, _, _ := strings.Cut(arg[1:], "=")
+			dash, multiChar = true, multiChar || len(name) > 1
+		case strings.HasPrefix(arg, "+") && len(arg) > 1 && isLetter(arg[1]):
+			dig = true
+		case strings.HasPrefix(arg, "/") && len(arg) > 1:
+			name, _, _ := strings.Cut(arg[1:], ":")
+			slash = slash || !strings.Contains(name, "/")
+		}
+	}
+
+	switch {
+	case dig:
+		return "dig", &Scanner{Prefixes: []string{"-", "--", "+"}, Separator: "--"}
+	case slash && !dash:
+		return "windows", NewWindowsScanner()
+	case multiChar && !doubleDash:
+		return "go", &Scanner{Prefixes: []string{"-"}, Separator: "--"}
+	default:
+		return "gnu", &Scanner{Prefixes: []string{"-", "--"}, Separator: "--"}
+	}
+}
+
+// isLetter returns whether ch is an ASCII letter.
+func isLetter(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
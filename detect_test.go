@@ -0,0 +1,76 @@
+// detect_test.go - Tests for detecting the command line style.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [DetectStyle] recognizes representative
+// command lines and returns a scanner configured accordingly.
+func TestDetectStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		first    Token
+	}{
+		{
+			name:     "dig",
+			args:     []string{"@8.8.8.8", "example.com", "+trace", "-t", "A"},
+			expected: "dig",
+			first:    PositionalArgumentToken{Idx: 0, Value: "@8.8.8.8"},
+		},
+		{
+			name:     "windows",
+			args:     []string{"/out:file.exe", "/debug", "main.obj"},
+			expected: "windows",
+			first:    OptionToken{Idx: 0, Prefix: "/", Name: "out", ValueSeparator: ":", Value: "file.exe"},
+		},
+		{
+			name:     "go",
+			args:     []string{"-verbose", "-file=x", "input.txt"},
+			expected: "go",
+			first:    OptionToken{Idx: 0, Prefix: "-", Name: "verbose"},
+		},
+		{
+			name:     "gnu",
+			args:     []string{"-v", "--file=x", "input.txt"},
+			expected: "gnu",
+			first:    OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+		},
+		{
+			name:     "unix paths are not windows options",
+			args:     []string{"-v", "/etc/passwd"},
+			expected: "gnu",
+			first:    OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+		},
+		{
+			name:     "arguments after the separator are ignored",
+			args:     []string{"-v", "--", "+trace", "-verbose"},
+			expected: "gnu",
+			first:    OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+		},
+		{
+			name:     "no options",
+			args:     []string{"file.txt"},
+			expected: "gnu",
+			first:    PositionalArgumentToken{Idx: 0, Value: "file.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			style, scanner := DetectStyle(tt.args)
+			if style != tt.expected {
+				t.Fatalf("DetectStyle(%q) = %q, want %q", tt.args, style, tt.expected)
+			}
+			tokens := scanner.Scan(tt.args)
+			if len(tokens) == 0 || !reflect.DeepEqual(tokens[0], tt.first) {
+				t.Errorf("Scan(%q) = %#v, want first token %#v", tt.args, tokens, tt.first)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// diagnostics.go - Aggregating errors and warnings.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Severity is the severity of a [Diagnostic].
+type Severity int
+
+const (
+	// SeverityError indicates a malformed argument (see [*Scanner.ScanStrict]).
+	SeverityError Severity = iota
+
+	// SeverityWarning indicates a suspicious argument (see [*Scanner.ScanWithWarnings]).
+	SeverityWarning
+)
+
+// String returns "error" or "warning".
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Diagnostic describes an issue with an argument found by [*Scanner.ScanDiagnostics].
+type Diagnostic struct {
+	// Severity is the severity of the issue.
+	Severity Severity
+
+	// Idx is the position in the original command line arguments.
+	Idx int
+
+	// Message describes the issue.
+	Message string
+
+	// Err is the underlying error (e.g., [ErrDeprecatedOption]).
+	Err error
+}
+
+// String returns the severity followed by the message (e.g.,
+// `warning: argument #0 ("-old"): option is deprecated: use -new`).
+func (d Diagnostic) String() string {
+	return d.Severity.String() + ": " + d.Message
+}
+
+// ScanDiagnostics is like [*Scanner.Scan] but also returns, in a single pass,
+// a [Diagnostic] for each error that [*Scanner.ScanStrict] would report and
+// for each warning that [*Scanner.ScanWithWarnings] would report, sorted by
+// Idx, with errors preceding warnings for the same argument. This allows
+// tools to present all the issues with the command line at once.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanDiagnostics(args []string) ([]Token, []Diagnostic) {
+	tokens, errs := sx.scan(args)
+	var diagnostics []Diagnostic
+	for _, err := range errs {
+		diagnostics = append(diagnostics, newDiagnostic(SeverityError, err))
+	}
+	for _, err := range sx.warnings(args, tokens) {
+		diagnostics = append(diagnostics, newDiagnostic(SeverityWarning, err))
+	}
+	slices.SortStableFunc(diagnostics, func(a, b Diagnostic) int {
+		return a.Idx - b.Idx
+	})
+	return tokens, diagnostics
+}
+
+// newDiagnostic creates a new [Diagnostic] from a [*ScanError].
+func newDiagnostic(severity Severity, err error) Diagnostic {
+	diagnostic := Diagnostic{Severity: severity, Message: err.Error(), Err: err}
+	var serr *ScanError
+	if errors.As(err, &serr) {
+		diagnostic.Idx, diagnostic.Err = serr.Idx, serr.Err
+	}
+	return diagnostic
+}
@@ -0,0 +1,87 @@
+// diagnostics_test.go - Tests for aggregating errors and warnings.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [*Scanner.ScanDiagnostics] aggregates
+// errors and warnings sorted by the argument index.
+func TestScannerScanDiagnostics(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:          []string{"-", "--"},
+		Separator:         "--",
+		GreedyPrefixRun:   true,
+		DeprecatedOptions: map[string]string{"old": "use --new"},
+	}
+	args := []string{"-v", "--old", "---x", "file"}
+	tokens, diagnostics := scanner.ScanDiagnostics(args)
+
+	if expect := scanner.Scan(args); !reflect.DeepEqual(tokens, expect) {
+		t.Errorf("ScanDiagnostics(%q) tokens = %#v, want %#v", args, tokens, expect)
+	}
+
+	if len(diagnostics) != 2 {
+		t.Fatalf("ScanDiagnostics(%q) = %v, want two diagnostics", args, diagnostics)
+	}
+	expect := []struct {
+		severity Severity
+		idx      int
+		err      error
+		message  string
+	}{
+		{
+			severity: SeverityWarning,
+			idx:      1,
+			err:      ErrDeprecatedOption,
+			message:  `warning: argument #1 ("--old"): option is deprecated: use --new`,
+		},
+		{
+			severity: SeverityError,
+			idx:      2,
+			err:      ErrExtraPrefixRun,
+			message:  `error: argument #2 ("---x"): leading prefix run does not match any configured prefix`,
+		},
+	}
+	for idx, want := range expect {
+		got := diagnostics[idx]
+		if got.Severity != want.severity || got.Idx != want.idx || !errors.Is(got.Err, want.err) {
+			t.Errorf("diagnostics[%d] = %#v, want severity %v, idx %d, and error %v",
+				idx, got, want.severity, want.idx, want.err)
+		}
+		if got.String() != want.message {
+			t.Errorf("diagnostics[%d].String() = %q, want %q", idx, got.String(), want.message)
+		}
+	}
+}
+
+// This test ensures that [*Scanner.ScanDiagnostics]
+// returns no diagnostics for a well formed command line.
+func TestScannerScanDiagnosticsClean(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-", "--"}, Separator: "--"}
+	_, diagnostics := scanner.ScanDiagnostics([]string{"-v", "--", "---x"})
+	if len(diagnostics) != 0 {
+		t.Errorf("ScanDiagnostics() = %v, want no diagnostics", diagnostics)
+	}
+}
+
+// This test ensures that [Severity] has a readable representation.
+func TestSeverityString(t *testing.T) {
+	tests := []struct {
+		severity Severity
+		expected string
+	}{
+		{SeverityError, "error"},
+		{SeverityWarning, "warning"},
+		{Severity(7), "Severity(7)"},
+	}
+	for _, tt := range tests {
+		if got := tt.severity.String(); got != tt.expected {
+			t.Errorf("Severity(%d).String() = %q, want %q", int(tt.severity), got, tt.expected)
+		}
+	}
+}
@@ -39,7 +39,7 @@ func ExampleScanner_dig() {
 
 	tokens := s.Scan(args[1:])
 	for _, token := range tokens {
-		fmt.Printf("%#v\n", token)
+		printToken(token)
 	}
 
 	// Output:
@@ -79,7 +79,7 @@ func ExampleScanner_gnu() {
 
 	tokens := s.Scan(args[1:])
 	for _, token := range tokens {
-		fmt.Printf("%#v\n", token)
+		printToken(token)
 	}
 
 	// Output:
@@ -114,7 +114,7 @@ func ExampleScanner_go() {
 
 	tokens := s.Scan(args[1:])
 	for _, token := range tokens {
-		fmt.Printf("%#v\n", token)
+		printToken(token)
 	}
 
 	// Output:
@@ -150,7 +150,7 @@ func ExampleScanner_unix() {
 
 	tokens := s.Scan(args[1:])
 	for _, token := range tokens {
-		fmt.Printf("%#v\n", token)
+		printToken(token)
 	}
 
 	// Output:
@@ -160,3 +160,18 @@ func ExampleScanner_unix() {
 	// flagscanner.OptionToken{Idx:3, Prefix:"-", Name:"abc"}
 	// flagscanner.PositionalArgumentToken{Idx:4, Value:"input.txt"}
 }
+
+// printToken prints the most relevant fields of the token, which are
+// more readable than printing all the fields using the "%#v" verb.
+func printToken(token flagscanner.Token) {
+	switch tk := token.(type) {
+	case flagscanner.OptionToken:
+		fmt.Printf("%T{Idx:%d, Prefix:%q, Name:%q}\n", tk, tk.Idx, tk.Prefix, tk.Name)
+	case flagscanner.PositionalArgumentToken:
+		fmt.Printf("%T{Idx:%d, Value:%q}\n", tk, tk.Idx, tk.Value)
+	case flagscanner.OptionsArgumentsSeparatorToken:
+		fmt.Printf("%T{Idx:%d, Separator:%q}\n", tk, tk.Idx, tk.Separator)
+	default:
+		fmt.Printf("%T{Idx:%d}\n", tk, tk.Index())
+	}
+}
@@ -0,0 +1,146 @@
+// explain.go - Explaining how arguments are classified.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Explain returns, for each argument, a human-readable explanation of how
+// [*Scanner.Scan] classifies it and why (e.g., which prefix matched, why it
+// is a positional argument, or that it is the separator), which helps
+// debugging a configuration. The explanations are meant for humans and
+// their wording may change in the future.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) Explain(args []string) []string {
+	tokens, errs := sx.scan(args)
+	explanations := make([]string, len(args))
+	add := func(idx int, text string) {
+		if explanations[idx] != "" {
+			explanations[idx] += "; "
+		}
+		explanations[idx] += text
+	}
+
+	var stopped bool
+	for tidx, token := range tokens {
+		switch tk := token.(type) {
+		case OptionToken:
+			text := fmt.Sprintf("option %q: matched prefix %q", tk.Name, tk.Prefix)
+			next := tk.Idx + tk.NameConsumed
+			if tk.Extension != "" {
+				text = fmt.Sprintf("option %q: long option following %q", tk.Name, sx.WExtensionOption)
+			}
+			if tk.hasValue() {
+				text += fmt.Sprintf(" with value %q", tk.Value)
+			}
+			add(tk.Idx, text)
+			if next > tk.Idx {
+				add(next, fmt.Sprintf("name of option %q", tk.Name))
+			}
+			stopped = stopped || sx.TerminatingOptions[tk.Name]
+			for idx := next + 1; idx <= next+tk.Consumed; idx++ {
+				add(idx, fmt.Sprintf("value of option %q", tk.Name))
+			}
+
+		case OptionsArgumentsSeparatorToken:
+			if sx.ToggleOnSeparator {
+				add(tk.Idx, "separator: toggles option parsing")
+				stopped = !stopped
+				continue
+			}
+			add(tk.Idx, "separator: the remaining arguments are positional")
+			stopped = true
+
+		case SubcommandToken:
+			if sx.FirstArgIsVerb && tk.Idx == 0 {
+				add(tk.Idx, "subcommand: the first argument is the verb")
+				continue
+			}
+			add(tk.Idx, "subcommand: follows the separator")
+
+		case AssignmentToken:
+			add(tk.Idx, fmt.Sprintf("assignment: key %q with value %q", tk.Key, tk.Value))
+
+		case RawRemainderToken:
+			for idx := tk.Idx; idx < len(args); idx++ {
+				add(idx, "raw remainder: option parsing stopped")
+			}
+
+		case LiteralNextToken:
+			add(tk.Idx, "control: the next argument is positional")
+
+		case PositionalArgumentToken:
+			if tidx > 0 {
+				if marker, ok := tokens[tidx-1].(LiteralNextToken); ok {
+					add(tk.Idx, fmt.Sprintf("positional argument: follows %q", marker.Marker))
+					continue
+				}
+			}
+			add(tk.Idx, sx.explainPositional(tk, args[tk.Idx], stopped))
+			stopped = stopped || sx.StopAtFirstPositional || (sx.StopAtSubcommand && sx.KnownSubcommands[tk.Value])
+		}
+	}
+
+	// Arguments without any token have been skipped
+	var commented bool
+	for idx, explanation := range explanations {
+		if explanation != "" {
+			continue
+		}
+		commented = commented || (sx.CommentPrefix != "" && strings.HasPrefix(args[idx], sx.CommentPrefix))
+		if commented {
+			explanations[idx] = "skipped: comment"
+			continue
+		}
+		explanations[idx] = "skipped: empty argument"
+	}
+
+	for _, err := range errs {
+		var serr *ScanError
+		if errors.As(err, &serr) {
+			add(serr.Idx, "malformed: "+serr.Err.Error())
+		}
+	}
+	return explanations
+}
+
+// explainPositional explains why arg is the positional argument tk.
+func (sx *Scanner) explainPositional(tk PositionalArgumentToken, arg string, stopped bool) string {
+	switch {
+	case sx.DisableOptionParsing:
+		return "positional argument: option parsing disabled"
+	case stopped:
+		return "positional argument: option parsing stopped"
+	case sx.PositionalOnly:
+		return "positional argument: positional-only mode"
+	case sx.isEscapedPrefix(arg):
+		return fmt.Sprintf("positional argument: escaped prefix %q", arg[:len(arg)/2])
+	case slices.Contains(sx.Prefixes, arg):
+		return "positional argument: a prefix alone is not an option"
+	}
+	for _, prefix := range sx.sortedPrefixes() {
+		if !strings.HasPrefix(arg, prefix) || len(arg) <= len(prefix) {
+			continue
+		}
+		if sx.PrefixRequiresNonDigit[prefix] && isDigit(arg[len(prefix)]) {
+			return fmt.Sprintf("positional argument: prefix %q followed by a digit", prefix)
+		}
+		if sx.PathHeuristic && prefix == "/" && sx.looksLikePath(arg[len(prefix):]) {
+			return "positional argument: looks like a path"
+		}
+	}
+	return "positional argument: no prefix matched"
+}
+
+// isEscapedPrefix returns whether arg is a prefix escaped by doubling it
+// (see [Scanner.EscapeByDoublingPrefix]).
+func (sx *Scanner) isEscapedPrefix(arg string) bool {
+	half := arg[:len(arg)/2]
+	return sx.EscapeByDoublingPrefix && half != "" && arg == half+half && slices.Contains(sx.Prefixes, half)
+}
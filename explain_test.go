@@ -0,0 +1,199 @@
+// explain_test.go - Tests for explaining how arguments are classified.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [*Scanner.Explain] explains options,
+// positional arguments, consumed values, and the separator.
+func TestScannerExplain(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:         []string{"-", "--", "+"},
+		Separator:        "--",
+		SplitValues:      true,
+		OptionsWithArity: map[string]int{"f": 1},
+		GreedyPrefixRun:  true,
+	}
+	args := []string{"--file=x", "-f", "a.txt", "out", "+", "---x", "--", "-v"}
+
+	got := scanner.Explain(args)
+
+	expect := []string{
+		`option "file": matched prefix "--" with value "x"`,
+		`option "f": matched prefix "-" with value "a.txt"`,
+		`value of option "f"`,
+		`positional argument: no prefix matched`,
+		`positional argument: a prefix alone is not an option`,
+		`positional argument: no prefix matched; malformed: leading prefix run does not match any configured prefix`,
+		`separator: the remaining arguments are positional`,
+		`positional argument: option parsing stopped`,
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Explain(%q) = %q, want %q", args, got, expect)
+	}
+}
+
+// This test ensures that [*Scanner.Explain] explains escaped
+// prefixes, the raw remainder, and the toggling separator.
+func TestScannerExplainSpecialCases(t *testing.T) {
+	t.Run("escaped prefix and raw remainder", func(t *testing.T) {
+		scanner := &Scanner{
+			Prefixes:               []string{"+"},
+			Separator:              "--",
+			EscapeByDoublingPrefix: true,
+			CaptureRemainderAsRaw:  true,
+		}
+		args := []string{"++", "--", "a", "b"}
+		got := scanner.Explain(args)
+		expect := []string{
+			`positional argument: escaped prefix "+"`,
+			`separator: the remaining arguments are positional`,
+			`raw remainder: option parsing stopped`,
+			`raw remainder: option parsing stopped`,
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Explain(%q) = %q, want %q", args, got, expect)
+		}
+	})
+
+	t.Run("skipped empty argument", func(t *testing.T) {
+		scanner := &Scanner{Prefixes: []string{"-"}, SkipEmptyArguments: true}
+		args := []string{"-v", ""}
+		got := scanner.Explain(args)
+		expect := []string{
+			`option "v": matched prefix "-"`,
+			`skipped: empty argument`,
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Explain(%q) = %q, want %q", args, got, expect)
+		}
+	})
+
+	t.Run("comment", func(t *testing.T) {
+		scanner := &Scanner{Prefixes: []string{"-"}, CommentPrefix: "#", SkipEmptyArguments: true}
+		args := []string{"", "-v", "#", "-x"}
+		got := scanner.Explain(args)
+		expect := []string{
+			`skipped: empty argument`,
+			`option "v": matched prefix "-"`,
+			`skipped: comment`,
+			`skipped: comment`,
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Explain(%q) = %q, want %q", args, got, expect)
+		}
+	})
+
+	t.Run("extension option", func(t *testing.T) {
+		scanner := &Scanner{
+			Prefixes:         []string{"-", "--"},
+			OptionsWithArity: map[string]int{"file": 1},
+			WExtensionOption: "W",
+		}
+		args := []string{"-W", "file", "x", "y"}
+		got := scanner.Explain(args)
+		expect := []string{
+			`option "file": long option following "W" with value "x"`,
+			`name of option "file"`,
+			`value of option "file"`,
+			`positional argument: no prefix matched`,
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Explain(%q) = %q, want %q", args, got, expect)
+		}
+	})
+
+	t.Run("extension option with invisibles", func(t *testing.T) {
+		scanner := &Scanner{
+			Prefixes:               []string{"-", "--"},
+			StripLeadingInvisibles: true,
+			WExtensionOption:       "W",
+		}
+		args := []string{"\uFEFF-W", "foo", "bar"}
+		got := scanner.Explain(args)
+		expect := []string{
+			`option "foo": long option following "W"`,
+			`name of option "foo"`,
+			`positional argument: no prefix matched`,
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Explain(%q) = %q, want %q", args, got, expect)
+		}
+	})
+
+	t.Run("toggling separator", func(t *testing.T) {
+		scanner := &Scanner{
+			Prefixes:          []string{"-"},
+			Separator:         "--",
+			ToggleOnSeparator: true,
+			EmitEndToken:      true,
+		}
+		args := []string{"--", "-a", "--", "-b"}
+		got := scanner.Explain(args)
+		expect := []string{
+			`separator: toggles option parsing`,
+			`positional argument: option parsing stopped`,
+			`separator: toggles option parsing`,
+			`option "b": matched prefix "-"`,
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Explain(%q) = %q, want %q", args, got, expect)
+		}
+	})
+	t.Run("rejected prefixes", func(t *testing.T) {
+		scanner := &Scanner{
+			Prefixes:               []string{"-", "+", "/"},
+			PrefixRequiresNonDigit: map[string]bool{"+": true},
+			PathHeuristic:          true,
+		}
+		args := []string{"+1234", "/etc/passwd", "+trace", "/v"}
+		got := scanner.Explain(args)
+		expect := []string{
+			`positional argument: prefix "+" followed by a digit`,
+			`positional argument: looks like a path`,
+			`option "trace": matched prefix "+"`,
+			`option "v": matched prefix "/"`,
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Explain(%q) = %q, want %q", args, got, expect)
+		}
+	})
+	t.Run("disabled option parsing", func(t *testing.T) {
+		scanner := &Scanner{
+			Prefixes:                           []string{"-"},
+			Separator:                          "--",
+			DisableOptionParsing:               true,
+			DisableOptionParsingKeepsSeparator: true,
+		}
+		args := []string{"-v", "--", "-x"}
+		got := scanner.Explain(args)
+		expect := []string{
+			`positional argument: option parsing disabled`,
+			`separator: the remaining arguments are positional`,
+			`positional argument: option parsing disabled`,
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Explain(%q) = %q, want %q", args, got, expect)
+		}
+	})
+	t.Run("stop at first positional", func(t *testing.T) {
+		scanner := &Scanner{
+			Prefixes:              []string{"-"},
+			StopAtFirstPositional: true,
+		}
+		args := []string{"-v", "file", "-x"}
+		got := scanner.Explain(args)
+		expect := []string{
+			`option "v": matched prefix "-"`,
+			`positional argument: no prefix matched`,
+			`positional argument: option parsing stopped`,
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Explain(%q) = %q, want %q", args, got, expect)
+		}
+	})
+}
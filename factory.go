@@ -0,0 +1,41 @@
+// factory.go - Scanning into user-defined token types.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+// TokenFactory creates user-defined tokens for [*Scanner.ScanWith].
+type TokenFactory interface {
+	// NewOption creates the token for an option.
+	NewOption(idx int, prefix, name string) Token
+
+	// NewPositional creates the token for a positional argument.
+	NewPositional(idx int, value string) Token
+
+	// NewSeparator creates the token for the separator.
+	NewSeparator(idx int, sep string) Token
+}
+
+// ScanWith is like [*Scanner.Scan] but uses the factory to create the tokens
+// for options, positional arguments, and the separator, which allows callers
+// embedding the scanner in larger parsers to use their own token types.
+//
+// The factory only receives the option prefix and name, therefore callers
+// needing option values should not configure the [*Scanner] to split or consume
+// them. We emit the [RawRemainderToken], [SubcommandToken], [AssignmentToken],
+// [LiteralNextToken], and [EndToken] as is.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanWith(args []string, f TokenFactory) []Token {
+	tokens := sx.Scan(args)
+	for idx, token := range tokens {
+		switch tk := token.(type) {
+		case OptionToken:
+			tokens[idx] = f.NewOption(tk.Idx, tk.Prefix, tk.Name)
+		case PositionalArgumentToken:
+			tokens[idx] = f.NewPositional(tk.Idx, tk.Value)
+		case OptionsArgumentsSeparatorToken:
+			tokens[idx] = f.NewSeparator(tk.Idx, tk.Separator)
+		}
+	}
+	return tokens
+}
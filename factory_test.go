@@ -0,0 +1,70 @@
+// factory_test.go - Tests for scanning into user-defined token types.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// annotatedToken is a user-defined [Token] annotated with its kind.
+type annotatedToken struct {
+	idx  int
+	kind string
+	text string
+}
+
+// Index implements [Token].
+func (tk annotatedToken) Index() int {
+	return tk.idx
+}
+
+// String implements [Token].
+func (tk annotatedToken) String() string {
+	return tk.text
+}
+
+// annotatingFactory is a [TokenFactory] creating [annotatedToken].
+type annotatingFactory struct{}
+
+var _ TokenFactory = annotatingFactory{}
+
+// NewOption implements [TokenFactory].
+func (annotatingFactory) NewOption(idx int, prefix, name string) Token {
+	return annotatedToken{idx: idx, kind: "option", text: prefix + name}
+}
+
+// NewPositional implements [TokenFactory].
+func (annotatingFactory) NewPositional(idx int, value string) Token {
+	return annotatedToken{idx: idx, kind: "positional", text: value}
+}
+
+// NewSeparator implements [TokenFactory].
+func (annotatingFactory) NewSeparator(idx int, sep string) Token {
+	return annotatedToken{idx: idx, kind: "separator", text: sep}
+}
+
+// This test ensures that [*Scanner.ScanWith] uses the factory
+// and emits the other tokens as is.
+func TestScannerScanWith(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:     []string{"-", "--"},
+		Separator:    "--",
+		EmitEndToken: true,
+	}
+	args := []string{"-v", "file.txt", "--", "-x"}
+
+	got := scanner.ScanWith(args, annotatingFactory{})
+
+	expect := []Token{
+		annotatedToken{idx: 0, kind: "option", text: "-v"},
+		annotatedToken{idx: 1, kind: "positional", text: "file.txt"},
+		annotatedToken{idx: 2, kind: "separator", text: "--"},
+		annotatedToken{idx: 3, kind: "positional", text: "-x"},
+		EndToken{Idx: 4},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanWith(%q) = %#v, want %#v", args, got, expect)
+	}
+}
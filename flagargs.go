@@ -0,0 +1,74 @@
+// flagargs.go - Interoperability with the standard library flag package.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+// FromFlagArgs scans args using the same conventions of the standard library
+// [flag] package: options start with "-" or "--", which are equivalent, "--"
+// separates options from positional arguments, and the first positional
+// argument stops option parsing (see [Scanner.StopAtFirstPositional]).
+//
+// The args MUST NOT include the program name as the first argument.
+func FromFlagArgs(args []string) []Token {
+	sx := &Scanner{
+		Prefixes:              []string{"-", "--"},
+		Separator:             "--",
+		StopAtFirstPositional: true,
+	}
+	return sx.Scan(args)
+}
+
+// ToFlagArgs converts tokens back to arguments accepted by the standard library
+// [flag] package, applying the following normalization:
+//
+//  1. each [OptionToken] uses a single dash prefix (e.g., --verbose becomes -verbose)
+//     and a Value is attached using "=" (e.g., --port8080 becomes -port=8080),
+//     repeating the option Count times when counted (e.g., -vvv becomes -v -v -v)
+//     and keeping the NegationSuffix when negated (e.g., -x- stays -x-) as well as
+//     the DeclaredType, if any (e.g., --count:int=5 becomes -count:int=5)
+//
+//  2. the values consumed by an option with an arity greater than one follow
+//     the option as separate arguments (e.g., -p a b c stays -p a b c)
+//
+//  3. each [OptionsArgumentsSeparatorToken] becomes "--"
+//
+//  4. each [PositionalArgumentToken] is emitted unchanged
+//
+//  5. each [RawRemainderToken] expands to its Args
+//
+//  6. each [EndToken] is skipped
+//
+// Values attached using "=" are part of the option name, therefore "-file=x"
+// round trips unchanged. Note that the [flag] package stops parsing at the first
+// positional argument, therefore options following a positional argument are
+// seen as positional arguments by the [flag] package.
+func ToFlagArgs(tokens []Token) []string {
+	args := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		switch tk := token.(type) {
+		case OptionToken:
+			arg := "-" + tk.Name + tk.NegationSuffix
+			if tk.DeclaredType != "" {
+				arg += ":" + tk.DeclaredType
+			}
+			if tk.Value != "" || tk.ValueSeparator != "" {
+				arg += "=" + tk.Value
+			}
+			for range max(tk.Count, 1) {
+				args = append(args, arg)
+			}
+			if tk.Consumed > 1 {
+				args = append(args, tk.consumedValues()...)
+			}
+		case OptionsArgumentsSeparatorToken:
+			args = append(args, "--")
+		case RawRemainderToken:
+			args = append(args, tk.Args...)
+		case EndToken:
+			// nothing
+		default:
+			args = append(args, tk.String())
+		}
+	}
+	return args
+}
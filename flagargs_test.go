@@ -0,0 +1,178 @@
+// flagargs_test.go - Tests for flag package interoperability.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [FromFlagArgs] and [ToFlagArgs] round trip
+// arguments already using the [flag] package conventions.
+func TestFlagArgsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "boolean flag",
+			args: []string{"-v"},
+		},
+		{
+			name: "flag with value",
+			args: []string{"-file=x"},
+		},
+		{
+			name: "positionals",
+			args: []string{"a.txt", "b.txt"},
+		},
+		{
+			name: "mixed with separator",
+			args: []string{"-v", "-file=x", "a.txt", "--", "-b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToFlagArgs(FromFlagArgs(tt.args))
+			if !reflect.DeepEqual(got, tt.args) {
+				t.Errorf("ToFlagArgs(FromFlagArgs(%q)) = %q, want %q", tt.args, got, tt.args)
+			}
+		})
+	}
+}
+
+// This test ensures that [ToFlagArgs] normalizes double dash options to
+// single dash options and that the [flag] package accepts the result.
+func TestToFlagArgsNormalization(t *testing.T) {
+	tokens := FromFlagArgs([]string{"--verbose", "--file=x", "input.txt"})
+	got := ToFlagArgs(tokens)
+	expect := []string{"-verbose", "-file=x", "input.txt"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("ToFlagArgs() = %q, want %q", got, expect)
+	}
+
+	fset := flag.NewFlagSet("prog", flag.ContinueOnError)
+	verbose := fset.Bool("verbose", false, "")
+	file := fset.String("file", "", "")
+	if err := fset.Parse(got); err != nil {
+		t.Fatal(err)
+	}
+	if !*verbose || *file != "x" || fset.NArg() != 1 || fset.Arg(0) != "input.txt" {
+		t.Errorf("unexpected flag.FlagSet state: verbose=%v file=%q args=%q", *verbose, *file, fset.Args())
+	}
+}
+
+// This test ensures that [ToFlagArgs] attaches a nonempty value using "=".
+func TestToFlagArgsAttachedValue(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:                     []string{"--"},
+		LongOptionsWithAttachedValue: map[string]bool{"port": true},
+	}
+	got := ToFlagArgs(scanner.Scan([]string{"--port8080"}))
+	expect := []string{"-port=8080"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ToFlagArgs() = %q, want %q", got, expect)
+	}
+}
+
+// This test ensures that [ToFlagArgs] skips the [EndToken].
+func TestToFlagArgsEndToken(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:     []string{"-", "--"},
+		Separator:    "--",
+		EmitEndToken: true,
+	}
+	got := ToFlagArgs(scanner.Scan([]string{"-v", "a.txt"}))
+	expect := []string{"-v", "a.txt"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ToFlagArgs() = %q, want %q", got, expect)
+	}
+}
+
+// This test ensures that [ToFlagArgs] expands a [RawRemainderToken]
+// into the original arguments rather than joining them.
+func TestToFlagArgsRawRemainder(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:              []string{"-", "--"},
+		Separator:             "--",
+		CaptureRemainderAsRaw: true,
+	}
+	got := ToFlagArgs(scanner.Scan([]string{"-v", "--", "ls", "-la"}))
+	expect := []string{"-v", "--", "ls", "-la"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ToFlagArgs() = %q, want %q", got, expect)
+	}
+}
+
+// This test ensures that [ToFlagArgs] repeats a counted option.
+func TestToFlagArgsCountedOption(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:           []string{"-", "--"},
+		BundleShortOptions: true,
+		CountableFlags:     map[string]bool{"v": true},
+	}
+	got := ToFlagArgs(scanner.Scan([]string{"-vvv", "-v", "a.txt"}))
+	expect := []string{"-v", "-v", "-v", "-v", "a.txt"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ToFlagArgs() = %q, want %q", got, expect)
+	}
+}
+
+// This test ensures that [ToFlagArgs] keeps the negation suffix, so that
+// scanning the result again produces negated options.
+func TestToFlagArgsNegatedOption(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:       []string{"-", "--"},
+		NegationSuffix: "-",
+	}
+	args := []string{"-x-", "-y"}
+	got := ToFlagArgs(scanner.Scan(args))
+	if !reflect.DeepEqual(got, args) {
+		t.Fatalf("ToFlagArgs() = %q, want %q", got, args)
+	}
+	if tokens := scanner.Scan(got); !reflect.DeepEqual(tokens, scanner.Scan(args)) {
+		t.Errorf("Scan(%q) = %#v, want %#v", got, tokens, scanner.Scan(args))
+	}
+}
+
+// This test ensures that [ToFlagArgs] keeps the values consumed by an option
+// with an arity greater than one and the declared type.
+func TestToFlagArgsConsumedValuesAndDeclaredType(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:              []string{"-", "--"},
+		SplitValues:           true,
+		OptionsWithArity:      map[string]int{"p": 3},
+		InlineTypeAnnotations: true,
+	}
+	got := ToFlagArgs(scanner.Scan([]string{"-p", "a", "b", "c", "--count:int=5", "z"}))
+	expect := []string{"-p", "a", "b", "c", "-count:int=5", "z"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ToFlagArgs() = %q, want %q", got, expect)
+	}
+}
+
+// This test ensures that [FromFlagArgs] stops option parsing at the first
+// positional argument, like the [flag] package does.
+func TestFromFlagArgsStopsAtFirstPositional(t *testing.T) {
+	args := []string{"file", "-v"}
+	got := FromFlagArgs(args)
+	expect := []Token{
+		PositionalArgumentToken{Idx: 0, Value: "file"},
+		PositionalArgumentToken{Idx: 1, Value: "-v"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("FromFlagArgs(%q) = %#v, want %#v", args, got, expect)
+	}
+
+	fset := flag.NewFlagSet("prog", flag.ContinueOnError)
+	fset.Bool("v", false, "")
+	if err := fset.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fset.Args(), args) {
+		t.Errorf("flag.FlagSet.Args() = %q, want %q", fset.Args(), args)
+	}
+}
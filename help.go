@@ -0,0 +1,40 @@
+// help.go - Detecting help requests.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"slices"
+	"strings"
+)
+
+// WantsHelp returns whether any of the helpNames (by default, "help" and "h")
+// appears as an option preceding the separator, using the same conventions
+// of [FromFlagArgs]: options start with "-" or "--" and "--" separates options
+// from positional arguments. We ignore any value attached using "=", therefore
+// "--help=all" matches "help". We return on the first match without building
+// the tokens, which makes this function suitable for an early check.
+//
+// The args MUST NOT include the program name as the first argument.
+func WantsHelp(args []string, helpNames ...string) bool {
+	if len(helpNames) <= 0 {
+		helpNames = []string{"help", "h"}
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		name, found := strings.CutPrefix(arg, "--")
+		if !found {
+			name, found = strings.CutPrefix(arg, "-")
+		}
+		if !found || name == "" {
+			continue
+		}
+		name, _, _ = strings.Cut(name, "=")
+		if slices.Contains(helpNames, name) {
+			return true
+		}
+	}
+	return false
+}
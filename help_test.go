@@ -0,0 +1,69 @@
+// help_test.go - Tests for detecting help requests.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import "testing"
+
+// This test ensures that [WantsHelp] only detects the help
+// options preceding the separator.
+func TestWantsHelp(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		helpNames []string
+		expected  bool
+	}{
+		{
+			name:     "short help",
+			args:     []string{"-v", "-h", "file"},
+			expected: true,
+		},
+		{
+			name:     "long help",
+			args:     []string{"file", "--help"},
+			expected: true,
+		},
+		{
+			name:     "help with value",
+			args:     []string{"--help=all"},
+			expected: true,
+		},
+		{
+			name:     "help as positional",
+			args:     []string{"help", "h"},
+			expected: false,
+		},
+		{
+			name:     "help after the separator",
+			args:     []string{"-v", "--", "--help", "-h"},
+			expected: false,
+		},
+		{
+			name:      "custom help names",
+			args:      []string{"-h", "-?"},
+			helpNames: []string{"?"},
+			expected:  true,
+		},
+		{
+			name:      "custom help names not matching defaults",
+			args:      []string{"-h", "--help"},
+			helpNames: []string{"?"},
+			expected:  false,
+		},
+		{
+			name:     "no arguments",
+			args:     nil,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WantsHelp(tt.args, tt.helpNames...)
+			if got != tt.expected {
+				t.Errorf("WantsHelp(%q, %q) = %v, want %v", tt.args, tt.helpNames, got, tt.expected)
+			}
+		})
+	}
+}
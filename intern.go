@@ -0,0 +1,40 @@
+// intern.go - Interning option names.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"strings"
+	"sync"
+)
+
+// Interner deduplicates strings so that equal strings share the same
+// backing memory. The zero value is ready to use and an [*Interner] is
+// safe to use concurrently. See also [Scanner.Interner].
+//
+// An [*Interner] never forgets a string, therefore it grows without bound
+// when interning untrusted input. Use a new [*Interner] when this is a concern.
+type Interner struct {
+	// mu protects strings.
+	mu sync.Mutex
+
+	// strings maps each string to its interned copy.
+	strings map[string]string
+}
+
+// Intern returns the interned copy of value. We store a copy of value, so
+// that interning a substring (e.g., "file" from "--file=x") does not keep
+// the whole string alive for the lifetime of the [*Interner].
+func (in *Interner) Intern(value string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if interned, found := in.strings[value]; found {
+		return interned
+	}
+	if in.strings == nil {
+		in.strings = make(map[string]string)
+	}
+	interned := strings.Clone(value)
+	in.strings[interned] = interned
+	return interned
+}
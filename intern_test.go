@@ -0,0 +1,57 @@
+// intern_test.go - Tests for interning option names.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// This test ensures that [Scanner.Interner] makes equal option
+// names across scans share the same backing memory.
+func TestScannerInterner(t *testing.T) {
+	scan := func(scanner *Scanner) OptionToken {
+		// Clone so that each scan sees a distinct backing array
+		tokens := scanner.Scan([]string{strings.Clone("--verbose")})
+		return tokens[0].(OptionToken)
+	}
+	sameMemory := func(a, b string) bool {
+		return unsafe.StringData(a) == unsafe.StringData(b)
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		scanner := &Scanner{Prefixes: []string{"-", "--"}, Interner: &Interner{}}
+		first, second := scan(scanner), scan(scanner)
+		if first.Name != "verbose" || !sameMemory(first.Name, second.Name) {
+			t.Errorf("Name %q and %q do not share memory", first.Name, second.Name)
+		}
+		if first.Prefix != "--" || !sameMemory(first.Prefix, second.Prefix) {
+			t.Errorf("Prefix %q and %q do not share memory", first.Prefix, second.Prefix)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		scanner := &Scanner{Prefixes: []string{"-", "--"}}
+		first, second := scan(scanner), scan(scanner)
+		if sameMemory(first.Name, second.Name) {
+			t.Errorf("Name %q and %q unexpectedly share memory", first.Name, second.Name)
+		}
+	})
+}
+
+// This test ensures that [*Interner.Intern] copies the value, so that
+// interning a substring does not keep the original string alive.
+func TestInternerCopiesValue(t *testing.T) {
+	arg := "--file=" + strings.Repeat("x", 1024)
+	value := arg[2:6]
+	var in Interner
+	got := in.Intern(value)
+	if got != "file" {
+		t.Fatalf("Intern(%q) = %q, want %q", value, got, "file")
+	}
+	if unsafe.StringData(got) == unsafe.StringData(value) {
+		t.Errorf("Intern(%q) shares memory with the original string", value)
+	}
+}
@@ -0,0 +1,37 @@
+// payload.go - Annotating tokens with arbitrary data.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+// WithPayload is a [Token] wrapping another [Token] and annotating it with
+// an arbitrary Payload (e.g., the resolved definition of an option).
+//
+// The Index and String methods delegate to the wrapped [Token].
+type WithPayload struct {
+	// Token is the wrapped token.
+	Token
+
+	// Payload is the arbitrary annotation.
+	Payload any
+}
+
+var _ Token = WithPayload{}
+
+// AttachPayload returns a [WithPayload] wrapping the token and annotating it
+// with the payload. If the token is already a [WithPayload], we replace its
+// payload rather than wrapping it twice.
+func AttachPayload(token Token, payload any) WithPayload {
+	if wp, ok := token.(WithPayload); ok {
+		token = wp.Token
+	}
+	return WithPayload{Token: token, Payload: payload}
+}
+
+// PayloadOf returns the payload of a [WithPayload] token, if any.
+func PayloadOf(token Token) (any, bool) {
+	wp, ok := token.(WithPayload)
+	if !ok {
+		return nil, false
+	}
+	return wp.Payload, true
+}
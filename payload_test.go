@@ -0,0 +1,44 @@
+// payload_test.go - Tests for annotating tokens with arbitrary data.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [WithPayload] delegates to the wrapped
+// token and round trips the payload.
+func TestWithPayload(t *testing.T) {
+	type definition struct {
+		help string
+	}
+	token := OptionToken{Idx: 3, Prefix: "--", Name: "verbose"}
+
+	wrapped := AttachPayload(token, definition{help: "be verbose"})
+
+	if wrapped.Index() != 3 {
+		t.Errorf("WithPayload.Index() = %d, want %d", wrapped.Index(), 3)
+	}
+	if wrapped.String() != "--verbose" {
+		t.Errorf("WithPayload.String() = %q, want %q", wrapped.String(), "--verbose")
+	}
+	if !reflect.DeepEqual(wrapped.Token, token) {
+		t.Errorf("WithPayload.Token = %#v, want %#v", wrapped.Token, token)
+	}
+
+	payload, ok := PayloadOf(wrapped)
+	if !ok || payload != (definition{help: "be verbose"}) {
+		t.Errorf("PayloadOf() = (%#v, %v), want the attached definition", payload, ok)
+	}
+
+	rewrapped := AttachPayload(wrapped, 42)
+	if !reflect.DeepEqual(rewrapped.Token, token) || rewrapped.Payload != 42 {
+		t.Errorf("AttachPayload() = %#v, want the token with the new payload", rewrapped)
+	}
+
+	if _, ok := PayloadOf(token); ok {
+		t.Errorf("PayloadOf() = ok for a token without payload")
+	}
+}
@@ -0,0 +1,38 @@
+// presets.go - Preconfigured scanners for common command line styles.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+// NewWindowsScanner returns a new [*Scanner] for Windows-style command lines.
+//
+// Options use the "/" prefix and values follow ":" (e.g., "/out:file.exe"
+// becomes Name "out" and Value "file.exe"). The "/?" help option is a
+// regular option named "?". There is no separator.
+func NewWindowsScanner() *Scanner {
+	return &Scanner{
+		Prefixes:        []string{"/"},
+		SplitValues:     true,
+		ValueSeparators: []string{":"},
+	}
+}
+
+// NewPlan9Scanner returns a new [*Scanner] for Plan 9 command lines, as
+// parsed by the ARGBEGIN macro and by the rc shell builtins.
+//
+// Options use the "-" prefix, have single-character names, and may be bundled
+// (e.g., "-ab" is "-a" and "-b"). Unlike the UNIX style, there are no long
+// options (e.g., "--verbose" is a bundle starting with "-" as name), "--" only
+// acts as the separator, and the first positional argument, including a
+// lone "-", stops option parsing (e.g., "-v" in "file -v" is positional).
+//
+// Options taking a value consume the rest of the bundle (e.g., "-ofile")
+// or the next argument (e.g., "-o file"): list them in the returned
+// [*Scanner] OptionsWithArity with an arity of one.
+func NewPlan9Scanner() *Scanner {
+	return &Scanner{
+		Prefixes:              []string{"-"},
+		Separator:             "--",
+		BundleShortOptions:    true,
+		StopAtFirstPositional: true,
+	}
+}
@@ -0,0 +1,112 @@
+// presets_test.go - Tests for preconfigured scanners.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [NewWindowsScanner] handles common Windows command lines.
+func TestNewWindowsScanner(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "linker",
+			args: []string{"/out:file.exe", "/debug", "main.obj"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "/", Name: "out", ValueSeparator: ":", Value: "file.exe"},
+				OptionToken{Idx: 1, Prefix: "/", Name: "debug"},
+				PositionalArgumentToken{Idx: 2, Value: "main.obj"},
+			},
+		},
+		{
+			name: "help",
+			args: []string{"/?"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "/", Name: "?"},
+			},
+		},
+		{
+			name: "xcopy",
+			args: []string{"/s", "/e", `C:\src`, `D:\dst`},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "/", Name: "s"},
+				OptionToken{Idx: 1, Prefix: "/", Name: "e"},
+				PositionalArgumentToken{Idx: 2, Value: `C:\src`},
+				PositionalArgumentToken{Idx: 3, Value: `D:\dst`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewWindowsScanner().Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [NewPlan9Scanner] handles common Plan 9 command lines.
+func TestNewPlan9Scanner(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "mk",
+			args: []string{"-ak", "-f", "mkfile", "all", "-n"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "a"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "k"},
+				OptionToken{Idx: 1, Prefix: "-", Name: "f", Value: "mkfile", Consumed: 1},
+				PositionalArgumentToken{Idx: 3, Value: "all"},
+				PositionalArgumentToken{Idx: 4, Value: "-n"},
+			},
+		},
+		{
+			name: "attached value",
+			args: []string{"-vo8.out", "main.8"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "o", Value: "8.out"},
+				PositionalArgumentToken{Idx: 1, Value: "main.8"},
+			},
+		},
+		{
+			name: "lone dash stops option parsing",
+			args: []string{"-", "-v"},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "-"},
+				PositionalArgumentToken{Idx: 1, Value: "-v"},
+			},
+		},
+		{
+			name: "separator",
+			args: []string{"-v", "--", "-x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				PositionalArgumentToken{Idx: 2, Value: "-x"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewPlan9Scanner()
+			scanner.OptionsWithArity = map[string]int{"f": 1, "o": 1}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// query.go - Helpers for querying scanned tokens.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Collect maps and filters tokens into a typed slice.
+//
+// The fn function returns the mapped value and whether to include it.
+func Collect[T any](tokens []Token, fn func(Token) (T, bool)) []T {
+	var values []T
+	for _, token := range tokens {
+		if value, ok := fn(token); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Find returns the first token satisfying pred, if any.
+func Find(tokens []Token, pred func(Token) bool) (Token, bool) {
+	for _, token := range tokens {
+		if pred(token) {
+			return token, true
+		}
+	}
+	return nil, false
+}
+
+// FindAll returns all the tokens satisfying pred, in order.
+func FindAll(tokens []Token, pred func(Token) bool) []Token {
+	var found []Token
+	for _, token := range tokens {
+		if pred(token) {
+			found = append(found, token)
+		}
+	}
+	return found
+}
+
+// Subcommand returns the value and the token-slice index of the first
+// [PositionalArgumentToken] preceding the separator, if any, or of the
+// leading [SubcommandToken] emitted using [Scanner.FirstArgIsVerb].
+//
+// This is the subcommand of multi-command tools, possibly following
+// global options (e.g., "build" in "-v build ./...").
+func Subcommand(tokens []Token) (string, int, bool) {
+	for idx, token := range tokens {
+		switch tk := token.(type) {
+		case OptionsArgumentsSeparatorToken:
+			return "", 0, false
+		case PositionalArgumentToken:
+			return tk.Value, idx, true
+		case SubcommandToken:
+			return tk.Name, idx, true
+		}
+	}
+	return "", 0, false
+}
+
+// TrailingPositionals returns the maximal run of [PositionalArgumentToken]
+// at the end of the tokens, ignoring a final [EndToken]. The run stops at
+// any other token, such as the last option or the separator, therefore in
+// "-a x -b file1 file2" the result contains "file1" and "file2".
+func TrailingPositionals(tokens []Token) []PositionalArgumentToken {
+	start := len(tokens)
+	if start > 0 {
+		if _, ok := tokens[start-1].(EndToken); ok {
+			tokens = tokens[:start-1]
+			start--
+		}
+	}
+	for start > 0 {
+		if _, ok := tokens[start-1].(PositionalArgumentToken); !ok {
+			break
+		}
+		start--
+	}
+	return Collect(tokens[start:], func(token Token) (PositionalArgumentToken, bool) {
+		tk, ok := token.(PositionalArgumentToken)
+		return tk, ok
+	})
+}
+
+// Summary returns the number of tokens and the sum of the byte lengths
+// of their string representations, for logging or limiting resources.
+func Summary(tokens []Token) (count int, totalBytes int) {
+	for _, token := range tokens {
+		totalBytes += len(token.String())
+	}
+	return len(tokens), totalBytes
+}
+
+// LongestOptionName returns the maximum length, in runes rather than in
+// bytes, of the Name of any [OptionToken], for aligning help output to the
+// widest option, or zero when there are no options.
+func LongestOptionName(tokens []Token) int {
+	var longest int
+	for _, token := range tokens {
+		if tk, ok := token.(OptionToken); ok {
+			longest = max(longest, utf8.RuneCountInString(tk.Name))
+		}
+	}
+	return longest
+}
+
+// RepeatedValues returns, in order, the values of all the occurrences of the
+// named option (e.g., "A: 1" and "B: 2" for "--header 'A: 1' --header 'B: 2'"),
+// including all the values consumed by options with an arity greater than one.
+// We skip the occurrences without a value. See also [SettingsMap].
+//
+// Scan the options with [Scanner.OptionsWithArity] or [Scanner.SplitValues].
+func RepeatedValues(tokens []Token, name string) []string {
+	var values []string
+	for _, token := range tokens {
+		tk, ok := token.(OptionToken)
+		switch {
+		case !ok || tk.Name != name:
+			// nothing
+		case tk.Consumed > 1:
+			values = append(values, tk.ValueList...)
+		case tk.hasValue():
+			values = append(values, tk.Value)
+		}
+	}
+	return values
+}
+
+// ErrMalformedSetting indicates that the value of a setting
+// option lacks the "=" between key and value (see [SettingsMap]).
+var ErrMalformedSetting = errors.New("setting is not in the key=value form")
+
+// SettingsMap collects the values of all the occurrences of the named option,
+// each in the "key=value" form (e.g., "--set a=1 --set b=2"), into a map where
+// later occurrences override earlier ones. We return an error wrapping
+// [ErrMalformedSetting] for values lacking "=" or with an empty key.
+//
+// The option must have a value, therefore scan options such as "--set a=1"
+// with [Scanner.OptionsWithArity] and options such as "--set=a=1" with
+// [Scanner.SplitValues], which splits at the first value separator.
+func SettingsMap(tokens []Token, optionName string) (map[string]string, error) {
+	settings := make(map[string]string)
+	for _, token := range tokens {
+		tk, ok := token.(OptionToken)
+		if !ok || tk.Name != optionName {
+			continue
+		}
+		key, value, found := strings.Cut(tk.Value, "=")
+		if !found || key == "" || !tk.hasValue() {
+			return nil, fmt.Errorf("%w: option %q at argument #%d has value %q",
+				ErrMalformedSetting, tk.Name, tk.Idx, tk.Value)
+		}
+		settings[key] = value
+	}
+	return settings, nil
+}
@@ -0,0 +1,377 @@
+// query_test.go - Tests for querying scanned tokens.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// This test ensures that [Collect] maps and filters tokens.
+func TestCollect(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:  []string{"-", "--"},
+		Separator: "--",
+	}
+	tokens := scanner.Scan([]string{"-v", "--file", "a.txt", "--", "b.txt"})
+
+	t.Run("option names", func(t *testing.T) {
+		got := Collect(tokens, func(token Token) (string, bool) {
+			tk, ok := token.(OptionToken)
+			return tk.Name, ok
+		})
+		expect := []string{"v", "file"}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Collect() = %q, want %q", got, expect)
+		}
+	})
+
+	t.Run("positional values", func(t *testing.T) {
+		got := Collect(tokens, func(token Token) (string, bool) {
+			tk, ok := token.(PositionalArgumentToken)
+			return tk.Value, ok
+		})
+		expect := []string{"a.txt", "b.txt"}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("Collect() = %q, want %q", got, expect)
+		}
+	})
+}
+
+// This test ensures that [Find] returns the first matching token.
+func TestFind(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:  []string{"-", "--", "+"},
+		Separator: "--",
+	}
+	tokens := scanner.Scan([]string{"-v", "+trace", "a.txt", "+short"})
+
+	got, found := Find(tokens, func(token Token) bool {
+		tk, ok := token.(OptionToken)
+		return ok && tk.Prefix == "+"
+	})
+	expect := OptionToken{Idx: 1, Prefix: "+", Name: "trace"}
+	if !found || !reflect.DeepEqual(got, expect) {
+		t.Errorf("Find() = %#v, %v, want %#v, true", got, found, expect)
+	}
+
+	got, found = Find(tokens, func(token Token) bool {
+		tk, ok := token.(OptionToken)
+		return ok && tk.Prefix == "--"
+	})
+	if found || got != nil {
+		t.Errorf("Find() = %#v, %v, want nil, false", got, found)
+	}
+}
+
+// This test ensures that [FindAll] returns all the matching tokens.
+func TestFindAll(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:  []string{"-"},
+		Separator: "--",
+	}
+	tokens := scanner.Scan([]string{"a.txt", "-v", "b.go", "--", "c.txt"})
+
+	got := FindAll(tokens, func(token Token) bool {
+		tk, ok := token.(PositionalArgumentToken)
+		return ok && strings.HasSuffix(tk.Value, ".txt")
+	})
+	expect := []Token{
+		PositionalArgumentToken{Idx: 0, Value: "a.txt"},
+		PositionalArgumentToken{Idx: 4, Value: "c.txt"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("FindAll() = %#v, want %#v", got, expect)
+	}
+
+	if got := FindAll(tokens, func(Token) bool { return false }); got != nil {
+		t.Errorf("FindAll() = %#v, want nil", got)
+	}
+}
+
+// This test ensures that [Subcommand] finds the first positional
+// argument preceding the separator.
+func TestSubcommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expectValue string
+		expectIndex int
+		expectOK    bool
+	}{
+		{
+			name:        "after global options",
+			args:        []string{"-v", "build", "-o", "out", "./..."},
+			expectValue: "build",
+			expectIndex: 1,
+			expectOK:    true,
+		},
+		{
+			name:     "options only",
+			args:     []string{"-v", "--verbose"},
+			expectOK: false,
+		},
+		{
+			name:     "positional after separator",
+			args:     []string{"-v", "--", "build"},
+			expectOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:  []string{"-", "--"},
+				Separator: "--",
+			}
+			value, index, ok := Subcommand(scanner.Scan(tt.args))
+			if value != tt.expectValue || index != tt.expectIndex || ok != tt.expectOK {
+				t.Errorf("Subcommand() = (%q, %d, %v), want (%q, %d, %v)",
+					value, index, ok, tt.expectValue, tt.expectIndex, tt.expectOK)
+			}
+		})
+	}
+}
+
+// This test ensures that [Subcommand] returns the verb
+// emitted using [Scanner.FirstArgIsVerb].
+func TestSubcommandFirstArgIsVerb(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:       []string{"-"},
+		Separator:      "--",
+		FirstArgIsVerb: true,
+	}
+	value, index, ok := Subcommand(scanner.Scan([]string{"status", "-v", "file"}))
+	if value != "status" || index != 0 || !ok {
+		t.Errorf("Subcommand() = (%q, %d, %v), want (%q, %d, %v)", value, index, ok, "status", 0, true)
+	}
+}
+
+// This test ensures that [TrailingPositionals] returns the maximal
+// run of positional arguments at the end of the stream.
+func TestTrailingPositionals(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []PositionalArgumentToken
+	}{
+		{
+			name: "trailing operands after options",
+			args: []string{"-a", "x", "-b", "file1", "file2"},
+			expected: []PositionalArgumentToken{
+				{Idx: 3, Value: "file1"},
+				{Idx: 4, Value: "file2"},
+			},
+		},
+		{
+			name: "all positional",
+			args: []string{"a", "b"},
+			expected: []PositionalArgumentToken{
+				{Idx: 0, Value: "a"},
+				{Idx: 1, Value: "b"},
+			},
+		},
+		{
+			name:     "ending in an option",
+			args:     []string{"a", "-b"},
+			expected: nil,
+		},
+		{
+			name: "stopping at the separator",
+			args: []string{"a", "--", "b"},
+			expected: []PositionalArgumentToken{
+				{Idx: 2, Value: "b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:     []string{"-"},
+				Separator:    "--",
+				EmitEndToken: true,
+			}
+			got := TrailingPositionals(scanner.Scan(tt.args))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("TrailingPositionals() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Summary] counts the tokens and their bytes.
+func TestSummary(t *testing.T) {
+	t.Run("mixed stream", func(t *testing.T) {
+		scanner := &Scanner{
+			Prefixes:     []string{"-", "--"},
+			Separator:    "--",
+			EmitEndToken: true,
+		}
+		tokens := scanner.Scan([]string{"--größe", "file.txt", "--", "-v"})
+
+		count, totalBytes := Summary(tokens)
+
+		var expectBytes int
+		for _, token := range tokens {
+			expectBytes += len(token.String())
+		}
+		if count != 5 || totalBytes != expectBytes || totalBytes != 21 {
+			t.Errorf("Summary() = %d, %d, want 5, %d", count, totalBytes, expectBytes)
+		}
+	})
+
+	t.Run("empty stream", func(t *testing.T) {
+		if count, totalBytes := Summary(nil); count != 0 || totalBytes != 0 {
+			t.Errorf("Summary() = %d, %d, want 0, 0", count, totalBytes)
+		}
+	})
+}
+
+// This test ensures that [LongestOptionName] counts runes rather than bytes.
+func TestLongestOptionName(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{
+			name:     "ASCII names",
+			args:     []string{"-v", "--verbose", "file.txt", "--file=x"},
+			expected: 7,
+		},
+		{
+			name:     "multi-byte name",
+			args:     []string{"--größe", "--size"},
+			expected: 5,
+		},
+		{
+			name:     "no options",
+			args:     []string{"file.txt", "--", "--very-long-option"},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:    []string{"-", "--"},
+				Separator:   "--",
+				SplitValues: true,
+			}
+			got := LongestOptionName(scanner.Scan(tt.args))
+			if got != tt.expected {
+				t.Errorf("LongestOptionName(%q) = %d, want %d", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [RepeatedValues] returns the values
+// of the repeated option in order.
+func TestRepeatedValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		option   string
+		expected []string
+	}{
+		{
+			name:     "multiple occurrences",
+			args:     []string{"--header", "A: 1", "-v", "--header=B: 2", "--header", "C: 3"},
+			option:   "header",
+			expected: []string{"A: 1", "B: 2", "C: 3"},
+		},
+		{
+			name:     "single occurrence",
+			args:     []string{"-v", "--header", "A: 1"},
+			option:   "header",
+			expected: []string{"A: 1"},
+		},
+		{
+			name:     "multiple consumed values",
+			args:     []string{"--point", "1", "2", "--point=3"},
+			option:   "point",
+			expected: []string{"1", "2", "3"},
+		},
+		{
+			name:     "no occurrences",
+			args:     []string{"-v", "--header"},
+			option:   "header",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:         []string{"-", "--"},
+				Separator:        "--",
+				SplitValues:      true,
+				OptionsWithArity: map[string]int{"header": 1, "point": 2},
+			}
+			got := RepeatedValues(scanner.Scan(tt.args), tt.option)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("RepeatedValues(%q) = %q, want %q", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [SettingsMap] builds a map from
+// the key=value settings and rejects malformed settings.
+func TestSettingsMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected map[string]string
+		err      error
+	}{
+		{
+			name:     "multiple settings",
+			args:     []string{"--set", "a=1", "-v", "--set=b=2", "--set", "a=3", "--set", "c="},
+			expected: map[string]string{"a": "3", "b": "2", "c": ""},
+		},
+		{
+			name:     "no settings",
+			args:     []string{"-v"},
+			expected: map[string]string{},
+		},
+		{
+			name: "malformed setting",
+			args: []string{"--set", "a=1", "--set", "nokey"},
+			err:  ErrMalformedSetting,
+		},
+		{
+			name: "empty key",
+			args: []string{"--set", "=1"},
+			err:  ErrMalformedSetting,
+		},
+		{
+			name: "missing value",
+			args: []string{"--set"},
+			err:  ErrMalformedSetting,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:         []string{"-", "--"},
+				Separator:        "--",
+				SplitValues:      true,
+				OptionsWithArity: map[string]int{"set": 1},
+			}
+			got, err := SettingsMap(scanner.Scan(tt.args), "set")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("SettingsMap(%q) error = %v, want %v", tt.args, err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SettingsMap(%q) = %v, want %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// render.go - Rendering tokens as command line arguments.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// GNUForm returns the GNU canonical rendering of the option regardless of
+// the prefix used on the command line: a single dash for single-character
+// names (e.g., "-v"), a double dash for longer names (e.g., "--verbose"),
+// and "=value" when the option has a value (e.g., "--file=x"). For example,
+// the dig-style "+trace" option becomes "--trace". A negated option keeps
+// its NegationSuffix (e.g., "-x-"), which would otherwise flip its meaning.
+func (tk OptionToken) GNUForm() string {
+	prefix := "--"
+	if utf8.RuneCountInString(tk.Name) == 1 {
+		prefix = "-"
+	}
+	form := prefix + tk.Name + tk.NegationSuffix
+	if tk.hasValue() {
+		form += "=" + tk.Value
+	}
+	return form
+}
+
+// Join returns the command line arguments corresponding to the tokens, which is
+// the inverse of [*Scanner.Scan]. Options bundled using [Scanner.BundleShortOptions]
+// are joined back into a single argument, options following the [Scanner.WExtensionOption]
+// are preceded by their Extension (e.g., "-W" "verbose"), options are followed by their
+// Consumed values, and each [RawRemainderToken] expands to its arguments. An [EndToken]
+// does not produce any argument.
+func Join(tokens []Token) []string {
+	args, _ := join(tokens)
+	return args
+}
+
+// join implements [Join] and also returns whether each argument
+// comes from a [PositionalArgumentToken] that was originally quoted.
+func join(tokens []Token) ([]string, []bool) {
+	args := make([]string, 0, len(tokens))
+	quoted := make([]bool, 0, len(tokens))
+	for idx, token := range tokens {
+		switch tk := token.(type) {
+		case OptionToken:
+			if name := strings.TrimPrefix(tk.String(), tk.Prefix); tk.Extension != "" && tk.NameConsumed > 0 {
+				args = append(args, tk.Extension, name)
+			} else if tk.Extension != "" {
+				args = append(args, tk.Extension+name)
+			} else if tk.SubIdx > 0 && idx > 0 && tokens[idx-1].Index() == tk.Idx && len(args) > 0 {
+				args[len(args)-1] += strings.TrimPrefix(tk.String(), tk.Prefix)
+			} else {
+				args = append(args, tk.String())
+			}
+			args = append(args, tk.consumedValues()...)
+		case PositionalArgumentToken:
+			args = append(args, tk.String())
+			quoted = append(quoted, tk.Quoted)
+		case RawRemainderToken:
+			args = append(args, tk.Args...)
+		case EndToken:
+			// nothing
+		default:
+			args = append(args, tk.String())
+		}
+		for len(quoted) < len(args) {
+			quoted = append(quoted, false)
+		}
+	}
+	return args, quoted
+}
+
+// ToGetoptLong returns the arguments corresponding to the tokens normalized
+// for a getopt_long consumer (e.g., a C program invoked as a subprocess):
+//
+//  1. options with multi-character names use the "--name" or "--name=value" form,
+//     as do negated options, which keep their suffix (e.g., "-x-");
+//
+//  2. consecutive single-character options are grouped (e.g., "-ab") and
+//     a value ends the group as a separate argument (e.g., "-abf" "value");
+//
+//  3. the values consumed by options with an arity greater than one follow
+//     the option as separate arguments (e.g., "-p" "a" "b" "c");
+//
+//  4. positional arguments, including the [RawRemainderToken] arguments,
+//     follow the options after a "--" argument, emitted only if needed.
+//
+// As with [OptionToken.GNUForm], we render options regardless of the prefix
+// used on the command line.
+func ToGetoptLong(tokens []Token) []string {
+	var (
+		options     []string
+		positionals []string
+		group       string
+	)
+	flush := func() {
+		if group != "" {
+			options = append(options, "-"+group)
+			group = ""
+		}
+	}
+
+	for _, token := range tokens {
+		switch tk := token.(type) {
+		case OptionToken:
+			if utf8.RuneCountInString(tk.Name) != 1 || tk.Negated {
+				flush()
+				options = append(options, tk.GNUForm())
+			} else {
+				group += strings.Repeat(tk.Name, max(tk.Count, 1))
+				if tk.hasValue() {
+					flush()
+					options = append(options, tk.Value)
+				}
+			}
+			if tk.Consumed > 1 {
+				flush()
+				options = append(options, tk.ValueList...)
+			}
+		case RawRemainderToken:
+			positionals = append(positionals, tk.Args...)
+		case OptionsArgumentsSeparatorToken, EndToken:
+			// nothing
+		default:
+			positionals = append(positionals, tk.String())
+		}
+	}
+	flush()
+
+	if len(positionals) > 0 {
+		options = append(options, "--")
+	}
+	return append(options, positionals...)
+}
+
+// ShellQuote returns the arguments produced by [Join] as a single string
+// quoted according to the POSIX shell rules, suitable for logging or for
+// running the command again using "sh -c". Arguments containing characters
+// other than ASCII letters, digits, and "@%+=:,./_-" are single quoted.
+//
+// We also single quote each [PositionalArgumentToken] whose Quoted field is
+// set (e.g., by [*Scanner.ScanLineWithQuotes]), thus restoring the quoting
+// of the original command line when splitting, scanning, and quoting.
+func ShellQuote(tokens []Token) string {
+	args, quoted := join(tokens)
+	output := make([]string, 0, len(args))
+	for idx, arg := range args {
+		if quoted[idx] {
+			output = append(output, shellForceQuoteArg(arg))
+			continue
+		}
+		output = append(output, shellQuoteArg(arg))
+	}
+	return strings.Join(output, " ")
+}
+
+// shellQuoteArg quotes a single argument for the POSIX shell.
+func shellQuoteArg(arg string) string {
+	if arg != "" && strings.Trim(arg, shellSafeChars) == "" {
+		return arg
+	}
+	return shellForceQuoteArg(arg)
+}
+
+// shellForceQuoteArg single quotes a single argument for the POSIX shell.
+func shellForceQuoteArg(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// shellSafeChars contains the characters not requiring shell quoting.
+const shellSafeChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-"
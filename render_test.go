@@ -0,0 +1,258 @@
+// render_test.go - Tests for rendering tokens as command line arguments.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [OptionToken.GNUForm] returns the GNU canonical
+// rendering of options regardless of the original prefix.
+func TestOptionTokenGNUForm(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    OptionToken
+		expected string
+	}{
+		{
+			name:     "short flag",
+			token:    OptionToken{Prefix: "-", Name: "v"},
+			expected: "-v",
+		},
+		{
+			name:     "long flag",
+			token:    OptionToken{Prefix: "--", Name: "verbose"},
+			expected: "--verbose",
+		},
+		{
+			name:     "long flag with single dash",
+			token:    OptionToken{Prefix: "-", Name: "verbose"},
+			expected: "--verbose",
+		},
+		{
+			name:     "value-bearing flag",
+			token:    OptionToken{Prefix: "/", Name: "out", ValueSeparator: ":", Value: "file.exe"},
+			expected: "--out=file.exe",
+		},
+		{
+			name:     "short flag with value",
+			token:    OptionToken{Prefix: "-", Name: "f", Value: "file"},
+			expected: "-f=file",
+		},
+		{
+			name:     "dig plus option",
+			token:    OptionToken{Prefix: "+", Name: "trace"},
+			expected: "--trace",
+		},
+		{
+			name:     "negated short flag",
+			token:    OptionToken{Prefix: "-", Name: "x", Negated: true, NegationSuffix: "-"},
+			expected: "-x-",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.token.GNUForm()
+			if got != tt.expected {
+				t.Errorf("OptionToken.GNUForm() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Join] reconstructs the original arguments.
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		name    string
+		scanner *Scanner
+		args    []string
+	}{
+		{
+			name: "options positionals and separator",
+			scanner: &Scanner{
+				Prefixes:    []string{"-", "--"},
+				Separator:   "--",
+				SplitValues: true,
+			},
+			args: []string{"-v", "--file=x", "a.txt", "--", "-b"},
+		},
+		{
+			name: "bundles",
+			scanner: &Scanner{
+				Prefixes:           []string{"-", "--"},
+				BundleShortOptions: true,
+				SplitValues:        true,
+			},
+			args: []string{"-abc=d", "-x", "-yz"},
+		},
+		{
+			name: "raw remainder and end token",
+			scanner: &Scanner{
+				Prefixes:              []string{"-"},
+				Separator:             "--",
+				CaptureRemainderAsRaw: true,
+				EmitEndToken:          true,
+			},
+			args: []string{"exec", "--", "ls", "-la"},
+		},
+		{
+			name: "escaped prefix",
+			scanner: &Scanner{
+				Prefixes:               []string{"-", "+"},
+				EscapeByDoublingPrefix: true,
+			},
+			args: []string{"++", "-v", "--"},
+		},
+		{
+			name: "negation suffix",
+			scanner: &Scanner{
+				Prefixes:           []string{"-", "--"},
+				BundleShortOptions: true,
+				NegationSuffix:     "-",
+			},
+			args: []string{"-x-", "-x-y"},
+		},
+		{
+			name: "extension option",
+			scanner: &Scanner{
+				Prefixes:         []string{"-", "--"},
+				SplitValues:      true,
+				OptionsWithArity: map[string]int{"file": 1},
+				WExtensionOption: "W",
+			},
+			args: []string{"-W", "verbose", "-Wfile=x", "-W", "file", "y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Join(tt.scanner.Scan(tt.args))
+			if !reflect.DeepEqual(got, tt.args) {
+				t.Errorf("Join() = %q, want %q", got, tt.args)
+			}
+		})
+	}
+}
+
+// This test ensures that [ShellQuote] only quotes the arguments requiring it.
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "plain tokens",
+			args:     []string{"-v", "--file=x.txt", "a/b.txt"},
+			expected: "-v --file=x.txt a/b.txt",
+		},
+		{
+			name:     "value with spaces",
+			args:     []string{"my file.txt"},
+			expected: "'my file.txt'",
+		},
+		{
+			name:     "value with single quotes",
+			args:     []string{"it's"},
+			expected: `'it'\''s'`,
+		},
+		{
+			name:     "value with metacharacters",
+			args:     []string{"a;b", "$HOME", ""},
+			expected: `'a;b' '$HOME' ''`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{Prefixes: []string{"-", "--"}}
+			got := ShellQuote(scanner.Scan(tt.args))
+			if got != tt.expected {
+				t.Errorf("ShellQuote() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [ShellQuote] restores the quoting of the positional
+// arguments when round tripping a line through [*Scanner.ScanLineWithQuotes].
+func TestShellQuoteRoundTrip(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-", "--"}, Separator: "--"}
+	line := `-v --file x.txt "my file.txt" 'bare' plain -- "-n"`
+	tokens, err := scanner.ScanLineWithQuotes(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := ShellQuote(tokens)
+	expect := `-v --file x.txt 'my file.txt' 'bare' plain -- '-n'`
+	if got != expect {
+		t.Errorf("ShellQuote() = %q, want %q", got, expect)
+	}
+
+	original, err := SplitArgs(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip, err := SplitArgs(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roundTrip, original) {
+		t.Errorf("SplitArgs(%q) = %q, want %q", got, roundTrip, original)
+	}
+}
+
+// This test ensures that [ToGetoptLong] normalizes a mixed stream.
+func TestToGetoptLong(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "mixed stream",
+			args:     []string{"+trace", "file.txt", "-a", "-b", "--file", "x", "-o", "out", "-c", "--", "-v"},
+			expected: []string{"--trace", "-ab", "--file=x", "-o", "out", "-c", "--", "file.txt", "-v"},
+		},
+		{
+			name:     "options only",
+			args:     []string{"-vv", "--verbose"},
+			expected: []string{"-vv", "--verbose"},
+		},
+		{
+			name:     "negated option",
+			args:     []string{"-a", "-x-", "-b"},
+			expected: []string{"-a", "-x-", "-b"},
+		},
+		{
+			name:     "options with arity greater than one",
+			args:     []string{"-a", "-p", "a", "b", "c", "--point", "1", "2", "z"},
+			expected: []string{"-ap", "a", "b", "c", "--point", "1", "2", "--", "z"},
+		},
+		{
+			name:     "empty stream",
+			args:     nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:         []string{"-", "--", "+"},
+				Separator:        "--",
+				OptionsWithArity: map[string]int{"file": 1, "o": 1, "p": 3, "point": 2},
+				CountableFlags:   map[string]bool{"v": true},
+				NegationSuffix:   "-",
+			}
+			got := ToGetoptLong(scanner.Scan(tt.args))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ToGetoptLong(%q) = %q, want %q", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// response.go - Expanding response files.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultResponseFileMaxDepth is the default maximum nesting
+// depth of response files used by [ExpandResponseFiles].
+const DefaultResponseFileMaxDepth = 32
+
+// ErrResponseFileCycle indicates that a response file includes itself,
+// either directly or through other response files.
+var ErrResponseFileCycle = errors.New("response file includes itself")
+
+// ErrResponseFileTooDeep indicates that response files are nested
+// more deeply than allowed by [ExpandResponseFiles].
+var ErrResponseFileTooDeep = errors.New("response files nested too deeply")
+
+// ExpandResponseFiles returns a copy of args where each argument of the
+// form "@name" is replaced by the arguments contained in the named response
+// file, read using readFile (e.g., [os.ReadFile]) and split like [*Scanner.ScanFile]
+// does, which allows passing command lines too long for the operating system.
+//
+// Response files may include other response files up to maxDepth levels of
+// nesting (if not positive, we use [DefaultResponseFileMaxDepth]). We return an
+// error wrapping [ErrResponseFileTooDeep] or [ErrResponseFileCycle] and naming
+// the offending file when the nesting is too deep or contains a cycle.
+//
+// Since the expanded arguments replace the original ones, token indexes
+// obtained by scanning the result refer to the expanded arguments.
+func ExpandResponseFiles(args []string, readFile func(name string) ([]byte, error), maxDepth int) ([]string, error) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultResponseFileMaxDepth
+	}
+	return expandResponseFiles(args, readFile, maxDepth, nil)
+}
+
+// expandResponseFiles implements [ExpandResponseFiles] given the
+// chain of the response files including the current args.
+func expandResponseFiles(args []string, readFile func(name string) ([]byte, error),
+	maxDepth int, chain []string) ([]string, error) {
+	output := make([]string, 0, len(args))
+	for _, arg := range args {
+		name, found := strings.CutPrefix(arg, "@")
+		if !found || name == "" {
+			output = append(output, arg)
+			continue
+		}
+		if slices.Contains(chain, name) {
+			return nil, fmt.Errorf("%s: %w", name, ErrResponseFileCycle)
+		}
+		if len(chain) >= maxDepth {
+			return nil, fmt.Errorf("%s: %w", name, ErrResponseFileTooDeep)
+		}
+
+		data, err := readFile(name)
+		if err != nil {
+			return nil, err
+		}
+		words, err := splitWords(string(data), true)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		nested := make([]string, 0, len(words))
+		for _, word := range words {
+			nested = append(nested, word.value)
+		}
+
+		expanded, err := expandResponseFiles(nested, readFile, maxDepth, append(slices.Clip(chain), name))
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, expanded...)
+	}
+	return output, nil
+}
@@ -0,0 +1,119 @@
+// response_test.go - Tests for expanding response files.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeFiles returns a readFile function reading from files.
+func fakeFiles(files map[string]string) func(name string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		content, found := files[name]
+		if !found {
+			return nil, fs.ErrNotExist
+		}
+		return []byte(content), nil
+	}
+}
+
+// chainFiles returns files where "f0" includes "f1", and so on, until
+// the last file, which contains the "-v" argument.
+func chainFiles(count int) map[string]string {
+	files := make(map[string]string)
+	for idx := range count - 1 {
+		files[fmt.Sprintf("f%d", idx)] = fmt.Sprintf("@f%d", idx+1)
+	}
+	files[fmt.Sprintf("f%d", count-1)] = "-v"
+	return files
+}
+
+// This test ensures that [ExpandResponseFiles] expands nested response files.
+func TestExpandResponseFiles(t *testing.T) {
+	files := map[string]string{
+		"opts":   "--file 'a b.txt' # comment\n@nested",
+		"nested": "-v",
+	}
+	args := []string{"-x", "@opts", "@", "out.txt"}
+
+	got, err := ExpandResponseFiles(args, fakeFiles(files), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"-x", "--file", "a b.txt", "-v", "@", "out.txt"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ExpandResponseFiles(%q) = %q, want %q", args, got, expect)
+	}
+}
+
+// This test ensures that [ExpandResponseFiles] bounds the nesting depth.
+func TestExpandResponseFilesMaxDepth(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		maxDepth int
+		err      error
+		errFile  string
+	}{
+		{
+			name:     "chain within the limit",
+			files:    chainFiles(3),
+			maxDepth: 3,
+			err:      nil,
+		},
+		{
+			name:     "chain exceeding the limit",
+			files:    chainFiles(4),
+			maxDepth: 3,
+			err:      ErrResponseFileTooDeep,
+			errFile:  "f3",
+		},
+		{
+			name:     "chain exceeding the default limit",
+			files:    chainFiles(DefaultResponseFileMaxDepth + 1),
+			maxDepth: 0,
+			err:      ErrResponseFileTooDeep,
+			errFile:  fmt.Sprintf("f%d", DefaultResponseFileMaxDepth),
+		},
+		{
+			name:     "cycle",
+			files:    map[string]string{"f0": "@f1", "f1": "@f0"},
+			maxDepth: 0,
+			err:      ErrResponseFileCycle,
+			errFile:  "f0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandResponseFiles([]string{"@f0"}, fakeFiles(tt.files), tt.maxDepth)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ExpandResponseFiles() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				if !strings.HasPrefix(err.Error(), tt.errFile+": ") {
+					t.Errorf("ExpandResponseFiles() error = %v, want it to name %q", err, tt.errFile)
+				}
+				return
+			}
+			if expect := []string{"-v"}; !reflect.DeepEqual(got, expect) {
+				t.Errorf("ExpandResponseFiles() = %q, want %q", got, expect)
+			}
+		})
+	}
+}
+
+// This test ensures that [ExpandResponseFiles] returns read errors.
+func TestExpandResponseFilesReadError(t *testing.T) {
+	_, err := ExpandResponseFiles([]string{"@missing"}, fakeFiles(nil), 0)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ExpandResponseFiles() error = %v, want %v", err, fs.ErrNotExist)
+	}
+}
@@ -0,0 +1,25 @@
+// reverse.go - Scanning arguments right to left.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import "slices"
+
+// ScanReverse is like [*Scanner.Scan] but returns the tokens in reverse order,
+// which is useful to parsers processing the trailing positional arguments first
+// (e.g., to build a syntax tree bottom up). Each token keeps the Idx of its
+// argument in the original order and the tokens of a bundle are also reversed.
+//
+// We classify the arguments in the original order, therefore the separator
+// semantics do not change: the arguments following the separator in the
+// original order, which precede the separator in the returned tokens, are
+// positional arguments, while the arguments following the separator in the
+// returned tokens may be options. When using [Scanner.EmitEndToken], the
+// [EndToken] is the first token.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanReverse(args []string) []Token {
+	tokens := sx.Scan(args)
+	slices.Reverse(tokens)
+	return tokens
+}
@@ -0,0 +1,49 @@
+// reverse_test.go - Tests for scanning arguments right to left.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [*Scanner.ScanReverse] reverses the tokens
+// while preserving the classification and the original indices.
+func TestScannerScanReverse(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:           []string{"-", "--"},
+		Separator:          "--",
+		BundleShortOptions: true,
+		EmitEndToken:       true,
+	}
+	args := []string{"-ab", "file", "--", "-x", "last"}
+	got := scanner.ScanReverse(args)
+	expect := []Token{
+		EndToken{Idx: 5},
+		PositionalArgumentToken{Idx: 4, Value: "last"},
+		PositionalArgumentToken{Idx: 3, Value: "-x"},
+		OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+		PositionalArgumentToken{Idx: 1, Value: "file"},
+		OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "b"},
+		OptionToken{Idx: 0, Prefix: "-", Name: "a"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanReverse(%q) = %#v, want %#v", args, got, expect)
+	}
+
+	forward := scanner.Scan(args)
+	for idx, token := range got {
+		if other := forward[len(forward)-1-idx]; token.Index() != other.Index() {
+			t.Errorf("ScanReverse(%q)[%d].Index() = %d, want %d", args, idx, token.Index(), other.Index())
+		}
+	}
+}
+
+// This test ensures that [*Scanner.ScanReverse] handles empty arguments.
+func TestScannerScanReverseEmpty(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-"}}
+	if got := scanner.ScanReverse(nil); len(got) != 0 {
+		t.Errorf("ScanReverse(nil) = %#v, want no tokens", got)
+	}
+}
@@ -18,6 +18,9 @@ to implement custom parsing logic on top of the tokenized stream.
 
  3. [PositionalArgumentToken]: Everything else (positional arguments)
 
+Depending on the configuration, it may also produce [RawRemainderToken],
+[SubcommandToken], [AssignmentToken], [LiteralNextToken], and [EndToken].
+
 # Option Prefixes
 
 The [*Scanner] is configured with the option prefixes to use when tokenizing
@@ -57,28 +60,495 @@ produces the following tokens:
  7. [PositionalArgumentToken] file.txt
 
 Note that everything after the separator becomes a positional argument.
+
+# Option Values
+
+When [Scanner.SplitValues] is true, the [*Scanner] splits option names at
+the first value separator (by default "="), therefore "--file=x" becomes
+an [OptionToken] with Name "file" and Value "x".
+
+# Bundling
+
+When [Scanner.BundleShortOptions] is true, the [*Scanner] splits bundles of
+short options, therefore "-abc" becomes three [OptionToken] named "a", "b",
+and "c" sharing the same Idx and with increasing SubIdx.
+
+A bundle ending with a value such as "-abc=d" is ambiguous. When
+[Scanner.SplitValues] is true, the last bundled option takes the value,
+therefore we get "-a", "-b", and "-c" with value "d". Otherwise, "=" and
+"d" are also bundled options.
+
+# Strict Mode
+
+[*Scanner.Scan] never fails and classifies malformed arguments as
+positional arguments. Use [*Scanner.ScanStrict] to also get an error
+describing the first malformed argument.
+
+# Escaping Prefixes
+
+When [Scanner.EscapeByDoublingPrefix] is true, a doubled prefix (e.g., "++"
+when "+" is a prefix) is an escaped prefix and becomes a [PositionalArgumentToken]
+containing the prefix itself (e.g., "+") with the original argument as RawValue.
 */
 package flagscanner
 
 import (
+	"slices"
 	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Scanner is a command line scanner.
 //
 // We check for the separator first. Then for option prefixes
-// sorted by length (longest first).
+// sorted by length (longest first), unless PrefixLess says otherwise.
 type Scanner struct {
 	// Prefixes contains the prefixes delimiting options.
 	//
-	// If empty, we don't recognize any prefix.
+	// If empty, we don't recognize any prefix, which is a supported
+	// positional-only mode: every argument is a [PositionalArgumentToken]
+	// except the separator (see also PositionalOnly).
 	Prefixes []string
 
+	// PositionalOnly ignores the Prefixes, as if they were empty, thus
+	// expressing the intent of scanning only positional arguments and the
+	// separator and guarding against accidentally adding prefixes. Unlike
+	// [Scanner.DisableOptionParsing], the separator is still recognized.
+	PositionalOnly bool
+
+	// PrefixSwitchOptions maps option names to the prefixes replacing Prefixes
+	// for all the following arguments once we scan the option (e.g., "legacy"
+	// to "-" for a tool switching from the GNU style to the UNIX style after
+	// "--legacy"). The last scanned listed option wins. The [OptionToken]
+	// PrefixConfigIndex and DetectPrefixes still refer to Prefixes.
+	//
+	// If empty, the prefixes never change.
+	PrefixSwitchOptions map[string][]string
+
+	// PrefixLess, if not nil, replaces the default order in which we try
+	// prefixes (longest first, then alphabetically), allowing arbitrary
+	// precedence. The first matching prefix in this order wins. The function
+	// must impose a strict weak ordering, as required by [sort.SliceStable].
+	PrefixLess func(a, b string) bool
+
+	// ResolvePrefix, if not nil, chooses the prefix to use for arg among the
+	// candidates, which are the matching prefixes tied in the order in which
+	// we try prefixes (i.e., such that none precedes another). We invoke it only
+	// when there are multiple candidates and ignore a result not among them.
+	//
+	// Since the default order breaks ties alphabetically, this is only useful
+	// with a PrefixLess leaving some prefixes unordered (e.g., ordering by the
+	// prefix character regardless of the length).
+	ResolvePrefix func(arg string, candidates []string) string
+
 	// Separator contains the separator between options and arguments.
 	//
+	// We check for the separator before checking for prefixes, therefore
+	// the separator wins even when it is also a prefix. For example, with
+	// the "-" and "--" prefixes and the "-" separator, "-" is the separator
+	// rather than a positional argument, which would be the case without
+	// a separator because a prefix alone does not constitute an option.
+	//
 	// If empty, we don't recognize any separator.
 	Separator string
+
+	// AfterSeparatorEscapes contains the arguments (e.g., "--meta") that are
+	// options even after the separator, as a controlled exception to the rule
+	// that the arguments following the separator are positional. This applies
+	// whenever option parsing has stopped (e.g., also after TerminatingOptions)
+	// but not with CaptureRemainderAsRaw or ToggleOnSeparator. We emit a listed
+	// argument as an [OptionToken] with the first matching prefix (by default,
+	// the longest one), without splitting or consuming any value.
+	//
+	// Note that this breaks the guarantee that the separator protects the
+	// wrapped command arguments: a listed argument meant for the wrapped
+	// command is never passed through as a positional argument.
+	AfterSeparatorEscapes []string
+
+	// TreatExtraSeparatorDashesAsSeparator makes arguments consisting of the
+	// character repeated in the Separator, but longer than it (e.g., "---" and
+	// "----" with the "--" separator), also act as the separator. By default,
+	// "---" is an [OptionToken] with Prefix "--" and Name "-" when "--" is
+	// a prefix. We only consider separators made of a repeated character.
+	TreatExtraSeparatorDashesAsSeparator bool
+
+	// SeparatorOnlyAfterOptions makes the separator preceding any option
+	// an [OptionToken] with the separator as Prefix and an empty Name (i.e.,
+	// an empty long option), therefore the separator is only recognized after
+	// at least one option. For example, "-- a" starts with an empty option while
+	// "-v -- a" contains the separator. An empty option counts as an option,
+	// therefore the second "--" in "-- -- a" is the separator.
+	SeparatorOnlyAfterOptions bool
+
+	// StripLeadingInvisibles removes the leading Unicode format characters
+	// (e.g., the U+FEFF byte order mark or the U+200B zero width space), which
+	// may come from pasting from rich sources, from the arguments before
+	// classifying them, therefore "\uFEFF--flag" is an option. We save the
+	// original argument into the [OptionToken] RawName field or into the
+	// [PositionalArgumentToken] RawValue field. We do not strip the arguments
+	// following the separator.
+	StripLeadingInvisibles bool
+
+	// EmptyArgIsSeparator makes an empty argument act as the separator, for
+	// wrappers encoding the end of options as "". We emit an [OptionsArgumentsSeparatorToken]
+	// with an empty Separator. This setting takes precedence over SkipEmptyArguments.
+	EmptyArgIsSeparator bool
+
+	// SkipEmptyArguments drops the empty arguments preceding the separator,
+	// which therefore do not produce any token. We preserve the empty arguments
+	// following the separator, which belong to the wrapped command.
+	SkipEmptyArguments bool
+
+	// DisableOptionParsing makes every argument a [PositionalArgumentToken],
+	// which is useful to pass-through wrappers that sometimes need to forward
+	// all the arguments untouched (see also [*Scanner.Clone]). Unless using
+	// DisableOptionParsingKeepsSeparator, this includes the separator.
+	DisableOptionParsing bool
+
+	// DisableOptionParsingKeepsSeparator makes the first separator an
+	// [OptionsArgumentsSeparatorToken] when using DisableOptionParsing.
+	DisableOptionParsingKeepsSeparator bool
+
+	// CommentPrefix is the prefix (e.g., "#") starting a comment extending to
+	// the end of the command line: we drop the argument beginning with it and
+	// all the following arguments, which therefore do not produce any token.
+	// We check for comments before checking for prefixes, therefore a comment
+	// wins over an option using the same prefix. The separator takes precedence
+	// and the arguments following it are never comments.
+	//
+	// If empty, we don't recognize any comment.
+	CommentPrefix string
+
+	// TreatWhitespaceAsEmpty makes the arguments preceding the separator
+	// consisting only of whitespace (e.g., "   ") empty arguments, which
+	// are therefore subject to SkipEmptyArguments and EmptyArgIsSeparator:
+	//
+	//  1. alone, a whitespace-only argument is a [PositionalArgumentToken]
+	//     with an empty Value and the original argument as RawValue;
+	//
+	//  2. with SkipEmptyArguments, we drop whitespace-only arguments;
+	//
+	//  3. with EmptyArgIsSeparator, a whitespace-only argument is the separator.
+	//
+	// By default, a whitespace-only argument is a positional argument whose
+	// Value is the argument itself and SkipEmptyArguments does not drop it.
+	TreatWhitespaceAsEmpty bool
+
+	// InlineDisableToken is the argument (e.g., "++noopt") disabling option
+	// recognition for exactly the next argument, which is a positional argument
+	// even if it looks like an option, or the separator. We emit the argument
+	// itself as a [LiteralNextToken].
+	//
+	// If empty, we don't recognize any such argument.
+	InlineDisableToken string
+
+	// EscapeByDoublingPrefix enables escaping a prefix by doubling it.
+	//
+	// When true, an argument consisting of a configured prefix repeated
+	// twice (e.g., "++" when "+" is a prefix) becomes a positional argument
+	// containing the prefix itself (e.g., "+"). The separator takes precedence,
+	// therefore "--" is still the separator when "-" is a prefix and "--"
+	// is the separator.
+	EscapeByDoublingPrefix bool
+
+	// InlineTypeAnnotations splits a type annotation, separated by ":", from the
+	// option name into the [OptionToken] DeclaredType field, for self-describing
+	// syntaxes (e.g., "--count:int=5" becomes Name "count", DeclaredType "int",
+	// and Value "5" when SplitValues is true). We split the value first, therefore
+	// this setting has no effect when ":" is among the ValueSeparators.
+	InlineTypeAnnotations bool
+
+	// LongOptionsWithAttachedValue contains the names of long options
+	// accepting a value directly attached to the name (e.g., "--port8080").
+	//
+	// A long option is an option whose prefix is longer than one byte
+	// (e.g., "--"). When a long option name begins with a listed name, we
+	// use the longest listed name as the [OptionToken] Name and the rest of
+	// the argument as its Value. For example, with "port" listed, "--port8080"
+	// becomes Name "port" and Value "8080", while "--port" has no value.
+	//
+	// If empty, we don't recognize any attached value.
+	LongOptionsWithAttachedValue map[string]bool
+
+	// GreedyPrefixRun requires a prefix made of a repeated character
+	// (e.g., "-" or "--") to match the whole leading run of such character.
+	//
+	// For example, with the "-" and "--" prefixes, "-x" has prefix "-", "--x"
+	// has prefix "--", and "---x" does not match any prefix, therefore it is a
+	// positional argument and [*Scanner.ScanStrict] reports an error.
+	GreedyPrefixRun bool
+
+	// SplitValues enables splitting option names at the first occurrence
+	// of any of the ValueSeparators (e.g., "--file=x" becomes Name "file"
+	// and Value "x"). We split short options as well, therefore "-f=x" becomes
+	// Name "f" and Value "x" (see also BundleShortOptions). Splitting takes
+	// precedence over LongOptionsWithAttachedValue.
+	SplitValues bool
+
+	// ValueSeparators contains the separators between option names and values.
+	//
+	// If empty, and SplitValues is true, we use "=".
+	ValueSeparators []string
+
+	// TreatTrailingEqualsAsPending makes an option ending with a value
+	// separator (e.g., "--file=") take the next argument as its value. When
+	// there is no next argument, we set the [OptionToken] MissingValue field.
+	//
+	// This setting only has effect when SplitValues is true.
+	TreatTrailingEqualsAsPending bool
+
+	// ValueListDelimiter maps option names to the delimiter used to
+	// additionally split their value into the [OptionToken] ValueList
+	// (e.g., "include" mapped to "," splits "--include=a,b,c").
+	//
+	// This setting only has effect when SplitValues is true.
+	ValueListDelimiter map[string]string
+
+	// OptionsWithArity maps option names to the number of following
+	// arguments they consume as values (see also [ParseOptionSpec]).
+	//
+	// An option with arity one consumes the next argument as its Value
+	// unless it already has a value (e.g., "--file=x"). An option with
+	// larger arity consumes the following arguments into its ValueList.
+	// When there are not enough arguments, we do not consume any of them
+	// and set the [OptionToken] MissingValue field.
+	//
+	// If empty, options do not consume any following argument.
+	OptionsWithArity map[string]int
+
+	// OptionEnums maps option names to their allowed values (e.g., "color"
+	// to "always", "never", and "auto"). Use it along with SplitValues or
+	// [Scanner.OptionsWithArity] to obtain the values. An option whose value
+	// is not allowed is malformed (see [ErrInvalidEnumValue]), while we do not
+	// check options without a value or without a listed name.
+	//
+	// If empty, we don't check any value.
+	OptionEnums map[string][]string
+
+	// OptionMaxOccurrences maps option names to the maximum number of times
+	// they may appear (e.g., 1 for options that must not be repeated), which
+	// generalizes [DuplicateOptions]. Each occurrence beyond the limit is
+	// malformed (see [ErrTooManyOccurrences]). An option with a Count (see
+	// CountableFlags) counts as Count occurrences.
+	//
+	// If empty, options may appear any number of times.
+	OptionMaxOccurrences map[string]int
+
+	// PositionalNames contains the names of the positional arguments
+	// preceding the separator, in order (e.g., "SOURCE" and "DEST"), used
+	// to set the [PositionalArgumentToken] Name field. Extra positional
+	// arguments and positional arguments following the separator have
+	// an empty name.
+	//
+	// If empty, positional arguments have an empty name.
+	PositionalNames []string
+
+	// Canonicalize computes the [OptionToken] CanonicalName from its Name
+	// (e.g., lowercasing or replacing "-" with "_"), allowing parsers to
+	// look up options by CanonicalName while preserving Name for presentation.
+	//
+	// If nil, the CanonicalName is empty.
+	Canonicalize func(name string) string
+
+	// TransformPositional computes the [PositionalArgumentToken] Value from
+	// the original argument (e.g., lowercasing or trimming), which we save into
+	// the RawValue field. It applies to all the positional arguments, including
+	// the ones following the separator.
+	//
+	// If nil, the Value is the original argument and the RawValue is empty.
+	TransformPositional func(value string) string
+
+	// FoldName computes the [OptionToken] FoldedName from its Name for
+	// case-insensitive matching, preserving Name for presentation. It is
+	// meant for locale-aware folding (e.g., a [golang.org/x/text/cases.Caser]
+	// handling the Turkish dotless I), which a plain [strings.ToLower] gets wrong.
+	//
+	// If nil, the FoldedName is empty.
+	FoldName func(name string) string
+
+	// PrefixRequiresNonDigit contains the prefixes that only introduce an
+	// option when the following character is not an ASCII digit. For example,
+	// with "+" listed, "+1234" is a positional argument (e.g., a phone number)
+	// while "+trace" is an option.
+	//
+	// If empty, prefixes introduce options regardless of the following character.
+	PrefixRequiresNonDigit map[string]bool
+
+	// PathHeuristic makes arguments starting with the "/" prefix positional
+	// when they look like UNIX paths, i.e., when the name preceding any value
+	// separator contains another "/". For example, "/etc/passwd" is a positional
+	// argument, while "/verbose" and "/out:dir/file" (when SplitValues is true
+	// and ":" is a value separator) are options.
+	PathHeuristic bool
+
+	// TerminatingOptions contains the names of the options after which all
+	// the remaining arguments are positional, as if they were preceded by the
+	// separator (e.g., "-e" in "xterm -e ls -la").
+	//
+	// If empty, no option terminates option parsing.
+	TerminatingOptions map[string]bool
+
+	// CaptureRemainderAsRaw emits the arguments following the separator or
+	// any TerminatingOptions as a single [RawRemainderToken] rather than as
+	// individual positional arguments. We do not emit a [RawRemainderToken]
+	// when there are no remaining arguments.
+	CaptureRemainderAsRaw bool
+
+	// BundleShortOptions treats an option with a single-byte prefix and
+	// a multi-character name as a bundle of single-character options (e.g.,
+	// "-abc" becomes "-a", "-b", and "-c"), which share the same Idx and
+	// have increasing SubIdx. Within a bundle:
+	//
+	//  1. an option with positive [Scanner.OptionsWithArity] takes the rest
+	//     of the bundle, if any, as its value (e.g., "-ffile");
+	//
+	//  2. when SplitValues is true, a value separator assigns the rest of
+	//     the bundle to the preceding option (e.g., "-abc=d" gives "-c" the
+	//     "d" value);
+	//
+	//  3. the [Scanner.NegationSuffix] negates the preceding option (e.g.,
+	//     "-x-y" is a negated "-x" followed by "-y").
+	BundleShortOptions bool
+
+	// WExtensionOption is the single-character option name (e.g., "W")
+	// introducing a long option as in GNU getopt, therefore "-W verbose"
+	// and "-Wverbose" are equivalent to "--verbose": we emit an [OptionToken]
+	// with the "--" Prefix, the Idx of the argument containing the extension
+	// option, the Extension set to the prefix and the extension option (e.g.,
+	// "-W"), and NameConsumed set to 1 when the name is a separate argument.
+	// The extension option alone as the last argument or followed by the
+	// separator is a regular option.
+	//
+	// If empty, we don't recognize any extension option.
+	WExtensionOption string
+
+	// CountableFlags contains the single-character names of the options
+	// that may be repeated to increase a count (e.g., "v" for verbosity).
+	// With a single-byte prefix, an argument repeating a listed name (e.g.,
+	// "-vvv") becomes a single [OptionToken] with Count 3 and FromBundle true,
+	// while "-v" has Count 1 and FromBundle false, therefore "-vv" and "-v -v"
+	// add up to the same count while remaining distinguishable.
+	//
+	// If empty, the [OptionToken] Count is zero.
+	CountableFlags map[string]bool
+
+	// NegationSuffix is the suffix negating options with a single-byte prefix
+	// (e.g., "-" makes "-x-" an [OptionToken] with Name "x" and Negated true).
+	// We check for the suffix after splitting the value, therefore "-x-=1"
+	// is a negated "x" with value "1". See also BundleShortOptions.
+	//
+	// If empty, we don't recognize any negation.
+	NegationSuffix string
+
+	// StopAtFirstPositional makes all the arguments following the first
+	// positional argument positional, as if they were preceded by the separator,
+	// as with the Plan 9 ARGBEGIN macro and with POSIX getopt. By default, options
+	// and positional arguments may be interleaved (e.g., "file -v").
+	StopAtFirstPositional bool
+
+	// StopAtSubcommand makes all the arguments following a positional argument
+	// listed in KnownSubcommands positional, as if they were preceded by the
+	// separator, so that the subcommand parser can handle them.
+	StopAtSubcommand bool
+
+	// KnownSubcommands contains the subcommands for StopAtSubcommand.
+	KnownSubcommands map[string]bool
+
+	// FirstArgIsVerb emits the first argument as a [SubcommandToken] even if
+	// it starts with a prefix or is the separator (e.g., "status" in "status -v")
+	// and starts scanning options from the second argument.
+	FirstArgIsVerb bool
+
+	// SeparatorIntroducesSubcommand emits the argument following the separator,
+	// if any, as a [SubcommandToken] marking a sub-invocation, followed by the
+	// remaining arguments (e.g., "-- exec a b" emits "exec" as a [SubcommandToken]).
+	SeparatorIntroducesSubcommand bool
+
+	// ToggleOnSeparator makes each occurrence of the separator flip between
+	// parsing options and treating all arguments as positional, rather than
+	// permanently stopping option parsing. For example, in "a -- b -- -c",
+	// "b" is positional and "-c" is an option again. We emit a separator token
+	// for each occurrence and ignore SeparatorIntroducesSubcommand.
+	ToggleOnSeparator bool
+
+	// AssignmentChar makes positional arguments preceding the separator that
+	// contain it after a non-empty key (e.g., "FOO=bar" with "=") produce an
+	// [AssignmentToken] rather than a [PositionalArgumentToken], as in "make
+	// VAR=value" or "env FOO=bar". Options such as "--opt=x" are not affected.
+	//
+	// If empty, we don't recognize any assignment.
+	AssignmentChar string
+
+	// XStylePrefixes contains the JVM-style meta-prefixes, consisting of a
+	// configured prefix followed by some characters (e.g., "-X"), introducing
+	// options whose name is the whole rest of the argument. For example, with
+	// "-X" listed, "-Xmx512m" is an [OptionToken] with Prefix "-" and Name
+	// "Xmx512m" and we do not split values, debundle, or negate it.
+	//
+	// If empty, we don't recognize any meta-prefix.
+	XStylePrefixes map[string]bool
+
+	// SectionDelimiter separates independent sections of the command line
+	// arguments for [*Scanner.ScanSections] (e.g., "+++" in "toolA -v +++
+	// toolB -x").
+	//
+	// If empty, [*Scanner.ScanSections] returns a single section.
+	SectionDelimiter string
+
+	// StdinMarker is the argument conventionally denoting the standard input
+	// or output (e.g., "-"), which ErrorOnPrefixOnly does not consider malformed.
+	//
+	// If empty, we don't recognize any marker.
+	StdinMarker string
+
+	// ErrorOnPrefixOnly makes [*Scanner.ScanStrict] report an error for
+	// arguments consisting of a prefix only (e.g., a bare "+"), unless they
+	// are the separator or the StdinMarker. Such arguments are positional
+	// arguments since a prefix without a name does not constitute an option.
+	ErrorOnPrefixOnly bool
+
+	// DetectPrefixes contains the prefix-looking sequences (e.g., "%" or the
+	// "—" em dash) that make [*Scanner.ScanStrict] report an error for arguments
+	// starting with them when they are not among the Prefixes, which catches
+	// typos such as "—flag" or "%flag". Such arguments are positional arguments.
+	//
+	// If empty, we don't detect any unconfigured prefix.
+	DetectPrefixes []string
+
+	// Interner, if not nil, interns the [OptionToken] Prefix and Name, so that
+	// equal names across many scanned command lines share the same backing
+	// memory. This trades a locked map lookup for each option for reduced
+	// memory usage in high-volume scanners retaining the tokens.
+	Interner *Interner
+
+	// DeprecatedOptions maps the names of deprecated options to a message
+	// suggesting the replacement (e.g., "use --output instead"), which makes
+	// [*Scanner.ScanWithWarnings] warn about each occurrence of such options.
+	//
+	// If empty, no option is deprecated.
+	DeprecatedOptions map[string]string
+
+	// RecordPrefixConfigIndex sets the [OptionToken] PrefixConfigIndex field
+	// to the position of the matched prefix within Prefixes, which may differ
+	// from the order in which we try prefixes (i.e., longest first).
+	RecordPrefixConfigIndex bool
+
+	// RecordForm sets the [OptionToken] Form field according to the length
+	// of the prefix: [ShortForm] for single-byte prefixes (e.g., "-v") and
+	// [LongForm] for longer prefixes (e.g., "--verbose"). We only consider
+	// the prefix, therefore dig-style options such as "+trace" are short.
+	RecordForm bool
+
+	// RecordPositionalIndex sets the [PositionalArgumentToken] PositionalIndex
+	// field to the number of positional arguments preceding it, including the
+	// ones following the separator, which differs from the Idx when options
+	// are interleaved (e.g., "b" in "-v a -x b" has Idx 3 and PositionalIndex 1).
+	RecordPositionalIndex bool
+
+	// EmitEndToken appends an [EndToken] after all the other tokens.
+	EmitEndToken bool
 }
 
 // Token is a token lexed by [*Scanner.Scan].
@@ -95,11 +565,89 @@ type OptionToken struct {
 	// Idx is the position in the original command line arguments.
 	Idx int
 
+	// SubIdx is the position within a bundle of short options
+	// when using [Scanner.BundleShortOptions], or zero.
+	SubIdx int
+
 	// Prefix is the scanned prefix.
 	Prefix string
 
+	// Form is the form of the option when using [Scanner.RecordForm],
+	// or [UnknownForm].
+	Form OptionForm
+
+	// PrefixConfigIndex is the position of the matched prefix within
+	// [Scanner.Prefixes] when using [Scanner.RecordPrefixConfigIndex], or zero.
+	// For an option following the [Scanner.WExtensionOption], the matched
+	// prefix is the one preceding it. The index is -1 when the matched prefix
+	// is not within Prefixes (e.g., after one of the [Scanner.PrefixSwitchOptions]).
+	PrefixConfigIndex int
+
 	// Name is the parsed name.
 	Name string
+
+	// DeclaredType is the type annotation following the name
+	// when using [Scanner.InlineTypeAnnotations], if any.
+	DeclaredType string
+
+	// Negated indicates that the option ended with the [Scanner.NegationSuffix].
+	Negated bool
+
+	// NegationSuffix is the [Scanner.NegationSuffix] that negated the option,
+	// if any, which allows rendering the option as it was scanned.
+	NegationSuffix string
+
+	// Count is the number of repetitions of an option listed in
+	// [Scanner.CountableFlags] (e.g., 2 for "-vv"), or zero.
+	Count int
+
+	// FromBundle indicates that the option listed in [Scanner.CountableFlags]
+	// was repeated within a single argument (e.g., "-vv" rather than "-v -v").
+	FromBundle bool
+
+	// ValueSeparator is the separator between Name and Value, if any.
+	ValueSeparator string
+
+	// Value is the value attached to the name, if any.
+	Value string
+
+	// MissingValue indicates that the option requires a value
+	// following it but there are no more arguments.
+	MissingValue bool
+
+	// Consumed is the number of arguments following the option
+	// consumed as its values (e.g., 1 for "--file x"), or zero.
+	Consumed int
+
+	// Extension is the prefix followed by the [Scanner.WExtensionOption]
+	// introducing the long option (e.g., "-W" for both "-Wverbose" and
+	// "-W verbose"), if any.
+	Extension string
+
+	// NameConsumed is the number of arguments following the option consumed
+	// as its name (i.e., 1 for "-W verbose"), or zero. The Consumed values
+	// follow such arguments.
+	NameConsumed int
+
+	// ValueList contains the Value split using the delimiter
+	// configured in [Scanner.ValueListDelimiter] or the values
+	// consumed according to [Scanner.OptionsWithArity], if any.
+	ValueList []string
+
+	// CanonicalName is the name computed by [Scanner.Canonicalize], if any.
+	CanonicalName string
+
+	// FoldedName is the name computed by [Scanner.FoldName], if any.
+	FoldedName string
+
+	// RawName is the original name when a transformation such
+	// as [ResolveAliases] has replaced the Name, or the original
+	// argument when [Scanner.StripLeadingInvisibles] has removed
+	// invisible characters from it, if any.
+	RawName string
+
+	// Origin is where the argument comes from, if known.
+	Origin
 }
 
 var _ Token = OptionToken{}
@@ -110,8 +658,80 @@ func (tk OptionToken) Index() int {
 }
 
 // String implements [Token].
+//
+// The result does not include the Consumed values, which are separate
+// arguments, therefore "--file x" becomes "--file". See also [Join].
+// The result includes the NegationSuffix, if any (e.g., "-x-"), and the
+// DeclaredType, if any (e.g., "--count:int=5").
 func (tk OptionToken) String() string {
-	return tk.Prefix + tk.Name
+	name := tk.Name
+	if tk.FromBundle && tk.Count > 1 {
+		name = strings.Repeat(name, tk.Count)
+	}
+	name += tk.NegationSuffix
+	if tk.DeclaredType != "" {
+		name += ":" + tk.DeclaredType
+	}
+	if tk.Consumed > 0 {
+		return tk.Prefix + name + tk.ValueSeparator
+	}
+	return tk.Prefix + name + tk.ValueSeparator + tk.Value
+}
+
+// consumedValues returns the Consumed values.
+func (tk OptionToken) consumedValues() []string {
+	switch {
+	case tk.Consumed == 1:
+		return []string{tk.Value}
+	case tk.Consumed > 1:
+		return tk.ValueList
+	default:
+		return nil
+	}
+}
+
+// ContainsValueSeparator returns whether the Name contains any of the given
+// value separators (by default, "="), which happens when scanning without
+// [Scanner.SplitValues] (e.g., "--file=config.txt" has Name "file=config.txt"),
+// allowing parsers to split values lazily. Pass the [Scanner.ValueSeparators],
+// if any, to use the same separators of the scanner.
+func (tk OptionToken) ContainsValueSeparator(separators ...string) bool {
+	if len(separators) <= 0 {
+		separators = []string{"="}
+	}
+	pos, _ := indexAny(tk.Name, separators)
+	return pos >= 0
+}
+
+// hasValue returns whether the option has a value.
+func (tk OptionToken) hasValue() bool {
+	return !tk.MissingValue && (tk.ValueSeparator != "" || tk.Value != "")
+}
+
+// OptionForm is the form of an [OptionToken] (see [Scanner.RecordForm]).
+type OptionForm int
+
+const (
+	// UnknownForm indicates that we did not record the form.
+	UnknownForm OptionForm = iota
+
+	// ShortForm indicates an option with a single-byte prefix (e.g., "-v").
+	ShortForm
+
+	// LongForm indicates an option with a longer prefix (e.g., "--verbose").
+	LongForm
+)
+
+// String returns the name of the form (e.g., "short").
+func (form OptionForm) String() string {
+	switch form {
+	case ShortForm:
+		return "short"
+	case LongForm:
+		return "long"
+	default:
+		return "unknown"
+	}
 }
 
 // PositionalArgumentToken is a [Token] containing a positional argument.
@@ -121,6 +741,25 @@ type PositionalArgumentToken struct {
 
 	// Value is the parsed value.
 	Value string
+
+	// RawValue is the original value when using [Scanner.TransformPositional],
+	// when [Scanner.StripLeadingInvisibles] has removed invisible characters, or
+	// when the value is an escaped prefix (see [Scanner.EscapeByDoublingPrefix]).
+	RawValue string
+
+	// Name is the name assigned using [Scanner.PositionalNames], if any.
+	Name string
+
+	// PositionalIndex is the 0-based position among the positional
+	// arguments when using [Scanner.RecordPositionalIndex], or zero.
+	PositionalIndex int
+
+	// Quoted indicates that the value was originally quoted when
+	// using [*Scanner.ScanLineWithQuotes].
+	Quoted bool
+
+	// Origin is where the argument comes from, if known.
+	Origin
 }
 
 var _ Token = PositionalArgumentToken{}
@@ -131,7 +770,12 @@ func (tk PositionalArgumentToken) Index() int {
 }
 
 // String implements [Token].
+//
+// We return the RawValue, if not empty, to reconstruct the original argument.
 func (tk PositionalArgumentToken) String() string {
+	if tk.RawValue != "" {
+		return tk.RawValue
+	}
 	return tk.Value
 }
 
@@ -142,6 +786,9 @@ type OptionsArgumentsSeparatorToken struct {
 
 	// Separator is the parsed separator.
 	Separator string
+
+	// Origin is where the argument comes from, if known.
+	Origin
 }
 
 var _ Token = OptionsArgumentsSeparatorToken{}
@@ -156,53 +803,834 @@ func (tk OptionsArgumentsSeparatorToken) String() string {
 	return tk.Separator
 }
 
+// RawRemainderToken is a [Token] containing the arguments following the separator
+// or a terminating option when using [Scanner.CaptureRemainderAsRaw].
+type RawRemainderToken struct {
+	// Idx is the position in the original command line arguments of the first argument.
+	Idx int
+
+	// Args contains the verbatim remaining arguments.
+	Args []string
+
+	// Origin is where the argument comes from, if known.
+	Origin
+}
+
+var _ Token = RawRemainderToken{}
+
+// Index implements [Token].
+func (tk RawRemainderToken) Index() int {
+	return tk.Idx
+}
+
+// String implements [Token].
+func (tk RawRemainderToken) String() string {
+	return strings.Join(tk.Args, " ")
+}
+
+// SubcommandToken is a [Token] containing a subcommand.
+type SubcommandToken struct {
+	// Idx is the position in the original command line arguments.
+	Idx int
+
+	// Name is the subcommand name.
+	Name string
+
+	// Origin is where the argument comes from, if known.
+	Origin
+}
+
+var _ Token = SubcommandToken{}
+
+// Index implements [Token].
+func (tk SubcommandToken) Index() int {
+	return tk.Idx
+}
+
+// String implements [Token].
+func (tk SubcommandToken) String() string {
+	return tk.Name
+}
+
+// AssignmentToken is a [Token] containing a "key=value" positional
+// argument when using [Scanner.AssignmentChar].
+type AssignmentToken struct {
+	// Idx is the position in the original command line arguments.
+	Idx int
+
+	// Key is the part preceding the [Scanner.AssignmentChar].
+	Key string
+
+	// Separator is the [Scanner.AssignmentChar].
+	Separator string
+
+	// Value is the part following the [Scanner.AssignmentChar].
+	Value string
+
+	// Origin is where the argument comes from, if known.
+	Origin
+}
+
+var _ Token = AssignmentToken{}
+
+// Index implements [Token].
+func (tk AssignmentToken) Index() int {
+	return tk.Idx
+}
+
+// String implements [Token].
+func (tk AssignmentToken) String() string {
+	return tk.Key + tk.Separator + tk.Value
+}
+
+// LiteralNextToken is a [Token] containing the [Scanner.InlineDisableToken],
+// which makes the next argument a [PositionalArgumentToken].
+type LiteralNextToken struct {
+	// Idx is the position in the original command line arguments.
+	Idx int
+
+	// Marker is the [Scanner.InlineDisableToken].
+	Marker string
+
+	// Origin is where the argument comes from, if known.
+	Origin
+}
+
+var _ Token = LiteralNextToken{}
+
+// Index implements [Token].
+func (tk LiteralNextToken) Index() int {
+	return tk.Idx
+}
+
+// String implements [Token].
+func (tk LiteralNextToken) String() string {
+	return tk.Marker
+}
+
+// EndToken is a [Token] marking the end of the command line arguments
+// when using [Scanner.EmitEndToken].
+type EndToken struct {
+	// Idx is the number of command line arguments.
+	Idx int
+}
+
+var _ Token = EndToken{}
+
+// Index implements [Token].
+func (tk EndToken) Index() int {
+	return tk.Idx
+}
+
+// String implements [Token].
+func (tk EndToken) String() string {
+	return ""
+}
+
 // Scan scans the command line arguments and returns a list of [Token].
 //
 // The args MUST NOT include the program name as the first argument.
 //
 // This method does not mutate the [*Scanner] and is safe to call concurrently.
 func (sx *Scanner) Scan(args []string) []Token {
-	// Create an empty list of tokens
-	tokens := make([]Token, 0, len(args))
+	tokens, _ := sx.scan(args)
+	return tokens
+}
 
-	// Create sorted copy of prefixes (longest first)
-	prefixes := make([]string, len(sx.Prefixes))
-	copy(prefixes, sx.Prefixes)
+// scan implements [*Scanner.Scan] and [*Scanner.ScanStrict].
+//
+// It returns the tokens along with the malformed arguments errors.
+func (sx *Scanner) scan(args []string) ([]Token, []error) {
+	var state scanState
+	tokens, errs := sx.scanArgs(args, &state)
+	if sx.EmitEndToken {
+		tokens = append(tokens, EndToken{Idx: len(args)})
+	}
+	return tokens, errs
+}
 
-	// Sort by length descending, then alphabetically for stability
-	sort.SliceStable(prefixes, func(i, j int) bool {
-		if len(prefixes[i]) == len(prefixes[j]) {
-			return prefixes[i] < prefixes[j]
+// scanState is the scanning state persisting across
+// batches of arguments (see [*StreamScanner.ScanBatch]).
+type scanState struct {
+	// operands counts the positional arguments preceding the separator.
+	operands int
+
+	// positionals counts all the positional arguments.
+	positionals int
+
+	// positional indicates that the separator toggled option parsing off.
+	positional bool
+
+	// sawOption indicates that we have already seen any option.
+	sawOption bool
+
+	// started indicates that we have already seen any argument.
+	started bool
+
+	// stopped indicates that option parsing has stopped.
+	stopped bool
+
+	// commented indicates that a comment started.
+	commented bool
+
+	// sorted indicates that prefixes has been initialized.
+	sorted bool
+
+	// prefixes contains the sorted prefixes, which we sort once rather than
+	// for each scanned range (e.g., for each [*TokenReader.Next] call) and
+	// replace when switching (see [Scanner.PrefixSwitchOptions]).
+	prefixes []string
+
+	// occurrences counts the occurrences of the options
+	// listed in [Scanner.OptionMaxOccurrences].
+	occurrences map[string]int
+}
+
+// scanArgs scans the arguments for [*Scanner.scan] updating the state.
+func (sx *Scanner) scanArgs(args []string, state *scanState) ([]Token, []error) {
+	tokens, errs, next := sx.scanRange(args, 0, len(args), state)
+
+	// Once option parsing has stopped, everything is an argument
+	if state.stopped {
+		tokens = sx.appendRemainder(tokens, args, next, state)
+	}
+	return tokens, errs
+}
+
+// scanRange scans the arguments starting at start and preceding end, along
+// with the following arguments they consume as values, updating the state.
+// It returns the index of the first argument it did not scan, which is
+// the first remaining argument when option parsing has stopped.
+func (sx *Scanner) scanRange(args []string, start, end int, state *scanState) ([]Token, []error, int) {
+	// Create an empty list of tokens and errors
+	tokens := make([]Token, 0, max(end-start, 0))
+	var errs []error
+
+	// Once a comment has started, we drop everything
+	if state.commented {
+		return tokens, errs, len(args)
+	}
+
+	// Once option parsing has stopped, everything is an argument
+	if state.stopped {
+		return tokens, errs, start
+	}
+
+	// Count the positional arguments preceding the separator
+	newOperand := func(idx int, value string) PositionalArgumentToken {
+		tk := sx.newPositional(state, idx, value)
+		original := args[idx]
+		stripped := sx.StripLeadingInvisibles && hasLeadingInvisibles(original)
+		blanked := sx.TreatWhitespaceAsEmpty && value == "" && original != ""
+		if tk.RawValue == "" && (stripped || blanked) {
+			tk.RawValue = original
 		}
-		return len(prefixes[i]) > len(prefixes[j])
-	})
+		if state.operands < len(sx.PositionalNames) {
+			tk.Name = sx.PositionalNames[state.operands]
+		}
+		state.operands++
+		return tk
+	}
+
+	// Without option parsing, everything is an argument except the separator
+	if sx.DisableOptionParsing {
+		for idx := start; idx < end; idx++ {
+			if arg := args[idx]; sx.DisableOptionParsingKeepsSeparator && sx.isSeparator(arg) {
+				tokens = append(tokens, OptionsArgumentsSeparatorToken{Idx: idx, Separator: arg})
+				state.stopped = true
+				return tokens, errs, idx + 1
+			}
+			tokens = append(tokens, newOperand(idx, args[idx]))
+		}
+		return tokens, errs, end
+	}
+
+	// Create sorted copy of prefixes (longest first) once
+	if !state.sorted {
+		state.prefixes, state.sorted = sx.sortedPrefixes(), true
+	}
+	prefixes := state.prefixes
 
-	// Cycle through the remaining arguments
+	// The first argument may be the verb regardless of its content
+	if sx.FirstArgIsVerb && !state.started && start < end {
+		tokens = append(tokens, SubcommandToken{Idx: start, Name: args[start]})
+		state.started = true
+		start++
+	}
+	state.started = state.started || start < end
+
+	// Cycle through the remaining arguments, creating the options of each
+	// argument within a reusable buffer to avoid allocating in the common case
+	var buffer [1]OptionToken
+	idx := start
 loop:
-	for idx, arg := range args {
-		// Check for separator first
-		if sx.Separator != "" && arg == sx.Separator {
+	for ; idx < end; idx++ {
+		arg := args[idx]
+		if sx.StripLeadingInvisibles {
+			arg = strings.TrimLeftFunc(arg, isInvisible)
+		}
+		if sx.TreatWhitespaceAsEmpty && strings.TrimSpace(arg) == "" {
+			arg = ""
+		}
+
+		// Check for separator first, which may be an empty option before any option
+		if sx.SeparatorOnlyAfterOptions && !state.sawOption && sx.Separator != "" && arg == sx.Separator {
+			tk := OptionToken{Idx: idx, Prefix: arg}
+			sx.finishOption(&tk)
+			tokens = append(tokens, tk)
+			state.sawOption = true
+			continue
+		}
+		if sx.isSeparator(arg) {
 			tokens = append(tokens, OptionsArgumentsSeparatorToken{Idx: idx, Separator: arg})
-			for tailIdx, tailArg := range args[idx+1:] {
-				tokens = append(tokens, PositionalArgumentToken{
-					Idx:   idx + 1 + tailIdx,
-					Value: tailArg,
-				})
+			if sx.ToggleOnSeparator {
+				state.positional = !state.positional
+				continue
+			}
+			next := idx + 1
+			if sx.SeparatorIntroducesSubcommand && next < len(args) {
+				tokens = append(tokens, SubcommandToken{Idx: next, Name: args[next]})
+				next++
+			}
+			state.stopped = true
+			return tokens, errs, next
+		}
+
+		// Between toggling separators, everything is an argument
+		if state.positional {
+			tokens = append(tokens, sx.newPositional(state, idx, arg))
+			continue
+		}
+
+		// A comment drops the remaining arguments
+		if sx.CommentPrefix != "" && strings.HasPrefix(arg, sx.CommentPrefix) {
+			state.commented = true
+			return tokens, errs, len(args)
+		}
+
+		// Empty arguments may be irrelevant
+		if sx.SkipEmptyArguments && arg == "" {
+			continue
+		}
+
+		// Then, check for an argument making the next one literal
+		if sx.InlineDisableToken != "" && arg == sx.InlineDisableToken {
+			tokens = append(tokens, LiteralNextToken{Idx: idx, Marker: arg})
+			if idx+1 < len(args) {
+				idx++
+				tokens = append(tokens, newOperand(idx, args[idx]))
+			}
+			continue
+		}
+
+		// Then, check for escaped (sorted) prefixes
+		if sx.EscapeByDoublingPrefix {
+			for _, prefix := range prefixes {
+				if prefix != "" && arg == prefix+prefix {
+					tk := newOperand(idx, prefix)
+					tk.RawValue = args[idx]
+					tokens = append(tokens, tk)
+					continue loop
+				}
 			}
-			return tokens
 		}
 
 		// Then, check for (sorted) prefixes with actual names
-		for _, prefix := range prefixes {
+		var rejected bool
+		for _, prefix := range sx.argPrefixes(prefixes, arg) {
 			if strings.HasPrefix(arg, prefix) && len(arg) > len(prefix) {
-				tokens = append(tokens, OptionToken{Idx: idx, Prefix: prefix, Name: arg[len(prefix):]})
+				if sx.PrefixRequiresNonDigit[prefix] && isDigit(arg[len(prefix)]) {
+					continue
+				}
+				if sx.PathHeuristic && prefix == "/" && sx.looksLikePath(arg[len(prefix):]) {
+					continue
+				}
+				if sx.GreedyPrefixRun && !isFullPrefixRun(arg, prefix) {
+					rejected = true
+					continue
+				}
+				var (
+					options    []OptionToken
+					terminated bool
+				)
+				original, first, matched := args[idx], idx, prefix
+				name, extension, nameConsumed := arg[len(prefix):], "", 0
+				if long, next, ok := sx.wExtension(args, idx, prefix, name); ok {
+					extension, nameConsumed = prefix+sx.WExtensionOption, next-idx
+					prefix, name, idx = "--", long, next
+				}
+				options, idx = sx.newOptionTokens(buffer[:0], args, idx, prefix, name)
+				state.sawOption = true
+				for _, tk := range options {
+					if original != arg {
+						tk.RawName = original
+					}
+					if extension != "" {
+						tk.Extension, tk.NameConsumed = extension, nameConsumed
+						if sx.RecordPrefixConfigIndex {
+							tk.PrefixConfigIndex = slices.Index(sx.Prefixes, matched)
+						}
+					}
+					tk.Idx = first
+					if err := sx.checkEnum(tk); err != nil {
+						errs = append(errs, &ScanError{Idx: first, Arg: original, Err: err})
+					}
+					if err := sx.checkOccurrences(tk, state); err != nil {
+						errs = append(errs, &ScanError{Idx: first, Arg: original, Err: err})
+					}
+					tokens = append(tokens, tk)
+					terminated = terminated || sx.TerminatingOptions[tk.Name]
+					if switched, found := sx.PrefixSwitchOptions[tk.Name]; found {
+						prefixes = sx.sortPrefixes(switched)
+						state.prefixes = prefixes
+					}
+				}
+				if terminated {
+					state.stopped = true
+					return tokens, errs, idx + 1
+				}
 				continue loop
 			}
 		}
+		if rejected {
+			errs = append(errs, &ScanError{Idx: idx, Arg: arg, Err: ErrExtraPrefixRun})
+		}
+		if sx.ErrorOnPrefixOnly && arg != sx.StdinMarker && slices.Contains(prefixes, arg) {
+			errs = append(errs, &ScanError{Idx: idx, Arg: arg, Err: ErrPrefixOnly})
+		}
+		if sx.hasUnknownPrefix(arg) {
+			errs = append(errs, &ScanError{Idx: idx, Arg: arg, Err: ErrUnknownPrefix})
+		}
+
+		// Assignments are a special kind of argument
+		if key, value, found := strings.Cut(arg, sx.AssignmentChar); sx.AssignmentChar != "" && found && key != "" {
+			tokens = append(tokens, AssignmentToken{Idx: idx, Key: key, Separator: sx.AssignmentChar, Value: value})
+			continue
+		}
 
 		// Everything else is an argument
-		tokens = append(tokens, PositionalArgumentToken{Idx: idx, Value: arg})
+		tokens = append(tokens, newOperand(idx, arg))
+
+		// Known subcommands parse their own options
+		if sx.StopAtFirstPositional || (sx.StopAtSubcommand && sx.KnownSubcommands[arg]) {
+			state.stopped = true
+			return tokens, errs, idx + 1
+		}
+	}
+
+	return tokens, errs, idx
+}
+
+// isSeparator returns whether arg acts as the separator.
+func (sx *Scanner) isSeparator(arg string) bool {
+	switch {
+	case sx.Separator != "" && arg == sx.Separator:
+		return true
+	case sx.EmptyArgIsSeparator && arg == "":
+		return true
+	case sx.TreatExtraSeparatorDashesAsSeparator && sx.Separator != "":
+		run := sx.Separator[:1]
+		return strings.Count(sx.Separator, run) == len(sx.Separator) &&
+			len(arg) > len(sx.Separator) && strings.Count(arg, run) == len(arg)
+	default:
+		return false
+	}
+}
+
+// sortedPrefixes returns a copy of the prefixes sorted by [Scanner.PrefixLess]
+// or, by default, by length descending, then alphabetically for stability.
+// It returns no prefixes when using [Scanner.PositionalOnly].
+func (sx *Scanner) sortedPrefixes() []string {
+	if sx.PositionalOnly {
+		return nil
+	}
+	return sx.sortPrefixes(sx.Prefixes)
+}
+
+// sortPrefixes returns a copy of the given prefixes sorted like [*Scanner.sortedPrefixes].
+func (sx *Scanner) sortPrefixes(input []string) []string {
+	prefixes := make([]string, len(input))
+	copy(prefixes, input)
+
+	less := sx.prefixLess()
+	sort.SliceStable(prefixes, func(i, j int) bool {
+		return less(prefixes[i], prefixes[j])
+	})
+	return prefixes
+}
+
+// prefixLess returns [Scanner.PrefixLess] or, by default, a function
+// ordering by length descending, then alphabetically for stability.
+func (sx *Scanner) prefixLess() func(a, b string) bool {
+	if sx.PrefixLess != nil {
+		return sx.PrefixLess
+	}
+	return func(a, b string) bool {
+		if len(a) == len(b) {
+			return a < b
+		}
+		return len(a) > len(b)
+	}
+}
+
+// argPrefixes returns the sorted prefixes in the order in which to try
+// them for arg, honouring the choice of [Scanner.ResolvePrefix], if any.
+func (sx *Scanner) argPrefixes(prefixes []string, arg string) []string {
+	if sx.ResolvePrefix == nil {
+		return prefixes
+	}
+
+	less := sx.prefixLess()
+	var candidates []string
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(arg, prefix) || len(arg) <= len(prefix) {
+			continue
+		}
+		if len(candidates) > 0 && less(candidates[0], prefix) {
+			break
+		}
+		candidates = append(candidates, prefix)
+	}
+	if len(candidates) < 2 {
+		return prefixes
+	}
+
+	chosen := sx.ResolvePrefix(arg, slices.Clone(candidates))
+	if !slices.Contains(candidates, chosen) {
+		return prefixes
+	}
+	order := make([]string, 0, len(prefixes))
+	order = append(order, chosen)
+	for _, prefix := range prefixes {
+		if prefix != chosen {
+			order = append(order, prefix)
+		}
 	}
+	return order
+}
 
+// appendRemainder appends the arguments starting at the given
+// index as positional arguments or as a [RawRemainderToken].
+func (sx *Scanner) appendRemainder(tokens []Token, args []string, start int, state *scanState) []Token {
+	if start >= len(args) {
+		return tokens
+	}
+	if sx.CaptureRemainderAsRaw {
+		return append(tokens, RawRemainderToken{Idx: start, Args: slices.Clone(args[start:])})
+	}
+	tail := args[start:]
+	tokens = slices.Grow(tokens, len(tail))
+	for tailIdx, tailArg := range tail {
+		tokens = append(tokens, sx.newRemainderToken(state, start+tailIdx, tailArg))
+	}
 	return tokens
 }
+
+// newRemainderToken creates the token for an argument following the separator,
+// which is a [PositionalArgumentToken] unless listed in [Scanner.AfterSeparatorEscapes].
+func (sx *Scanner) newRemainderToken(state *scanState, idx int, arg string) Token {
+	if slices.Contains(sx.AfterSeparatorEscapes, arg) {
+		for _, prefix := range sx.sortedPrefixes() {
+			if strings.HasPrefix(arg, prefix) && len(arg) > len(prefix) {
+				tk := OptionToken{Idx: idx, Prefix: prefix, Name: arg[len(prefix):]}
+				sx.finishOption(&tk)
+				return tk
+			}
+		}
+	}
+	return sx.newPositional(state, idx, arg)
+}
+
+// newPositional creates a [PositionalArgumentToken] applying the
+// [Scanner.TransformPositional], if any, and counting it into the state.
+func (sx *Scanner) newPositional(state *scanState, idx int, value string) PositionalArgumentToken {
+	tk := PositionalArgumentToken{Idx: idx, Value: value}
+	if sx.TransformPositional != nil {
+		tk.Value, tk.RawValue = sx.TransformPositional(value), value
+	}
+	if sx.RecordPositionalIndex {
+		tk.PositionalIndex = state.positionals
+	}
+	state.positionals++
+	return tk
+}
+
+// isFullPrefixRun returns whether the prefix, if made of a repeated
+// character, is the whole leading run of such character in arg.
+//
+// Prefixes not made of a repeated character always match.
+func isFullPrefixRun(arg, prefix string) bool {
+	if prefix == "" || strings.Count(prefix, prefix[:1]) != len(prefix) {
+		return true
+	}
+	return len(arg) <= len(prefix) || arg[len(prefix)] != prefix[0]
+}
+
+// newOptionTokens appends to options the [OptionToken] for the given prefix and
+// name, debundling short options and consuming the following values, if needed.
+// It returns the options and the index of the last consumed argument.
+func (sx *Scanner) newOptionTokens(options []OptionToken, args []string, idx int, prefix, name string) ([]OptionToken, int) {
+	start := len(options)
+	if sx.isXStyle(prefix + name) {
+		options = append(options, OptionToken{Idx: idx, Prefix: prefix, Name: name})
+	} else if count := sx.countRepeats(prefix, name); count > 1 {
+		options = append(options, OptionToken{
+			Idx:        idx,
+			Prefix:     prefix,
+			Name:       name[:len(name)/count],
+			Count:      count,
+			FromBundle: true,
+		})
+	} else if sx.BundleShortOptions && len(prefix) == 1 && utf8.RuneCountInString(name) > 1 {
+		options = append(options, sx.debundle(idx, prefix, name)...)
+	} else {
+		tk := sx.newOptionToken(idx, prefix, name)
+		if len(prefix) == 1 && sx.NegationSuffix != "" && len(tk.Name) > len(sx.NegationSuffix) {
+			if tk.Name, tk.Negated = strings.CutSuffix(tk.Name, sx.NegationSuffix); tk.Negated {
+				tk.NegationSuffix = sx.NegationSuffix
+			}
+		}
+		options = append(options, tk)
+	}
+	for sub := start; sub < len(options); sub++ {
+		idx = sx.consumeValues(&options[sub], args, idx)
+		sx.finishOption(&options[sub])
+	}
+	return options, idx
+}
+
+// finishOption applies to every [OptionToken] the processing that does not
+// depend on the following arguments (e.g., [Scanner.Canonicalize]).
+func (sx *Scanner) finishOption(tk *OptionToken) {
+	if sx.Interner != nil {
+		tk.Prefix = sx.Interner.Intern(tk.Prefix)
+		tk.Name = sx.Interner.Intern(tk.Name)
+	}
+	if sx.RecordForm {
+		tk.Form = ShortForm
+		if len(tk.Prefix) > 1 {
+			tk.Form = LongForm
+		}
+	}
+	if sx.RecordPrefixConfigIndex {
+		tk.PrefixConfigIndex = slices.Index(sx.Prefixes, tk.Prefix)
+	}
+	if tk.Count == 0 && sx.CountableFlags[tk.Name] {
+		tk.Count = 1
+	}
+	if sx.Canonicalize != nil {
+		tk.CanonicalName = sx.Canonicalize(tk.Name)
+	}
+	if sx.FoldName != nil {
+		tk.FoldedName = sx.FoldName(tk.Name)
+	}
+}
+
+// wExtension returns the long option name following the single-byte prefix
+// and the [Scanner.WExtensionOption], either attached (e.g., "-Wverbose") or as
+// the next argument (e.g., "-W verbose") unless it is the separator, and the
+// index of the argument containing it. The boolean is false when the option
+// is not an extension.
+func (sx *Scanner) wExtension(args []string, idx int, prefix, name string) (string, int, bool) {
+	if sx.WExtensionOption == "" || len(prefix) != 1 {
+		return "", idx, false
+	}
+	long, found := strings.CutPrefix(name, sx.WExtensionOption)
+	if !found {
+		return "", idx, false
+	}
+	if long != "" {
+		return long, idx, true
+	}
+	if idx+1 < len(args) && args[idx+1] != "" && !sx.isSeparator(args[idx+1]) {
+		return args[idx+1], idx + 1, true
+	}
+	return "", idx, false
+}
+
+// countRepeats returns how many times name repeats a single-character
+// name listed in [Scanner.CountableFlags], or zero.
+func (sx *Scanner) countRepeats(prefix, name string) int {
+	if len(prefix) != 1 || name == "" {
+		return 0
+	}
+	_, size := utf8.DecodeRuneInString(name)
+	flag := name[:size]
+	if !sx.CountableFlags[flag] || strings.Trim(name, flag) != "" {
+		return 0
+	}
+	return len(name) / size
+}
+
+// hasUnknownPrefix returns whether arg starts with any of the [Scanner.DetectPrefixes]
+// not listed in [Scanner.Prefixes] followed by at least one character.
+func (sx *Scanner) hasUnknownPrefix(arg string) bool {
+	for _, prefix := range sx.DetectPrefixes {
+		if len(arg) > len(prefix) && strings.HasPrefix(arg, prefix) && !slices.Contains(sx.Prefixes, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikePath returns whether the name following the "/"
+// prefix makes the argument look like a UNIX path.
+func (sx *Scanner) looksLikePath(name string) bool {
+	if sx.SplitValues {
+		if pos, _ := indexAny(name, sx.valueSeparators()); pos >= 0 {
+			name = name[:pos]
+		}
+	}
+	return strings.Contains(name, "/")
+}
+
+// isInvisible returns whether r is an invisible Unicode format character.
+func isInvisible(r rune) bool {
+	return unicode.Is(unicode.Cf, r)
+}
+
+// hasLeadingInvisibles returns whether arg starts with invisible characters.
+func hasLeadingInvisibles(arg string) bool {
+	r, _ := utf8.DecodeRuneInString(arg)
+	return arg != "" && isInvisible(r)
+}
+
+// isXStyle returns whether arg starts with any of the [Scanner.XStylePrefixes].
+func (sx *Scanner) isXStyle(arg string) bool {
+	for xprefix, ok := range sx.XStylePrefixes {
+		if ok && strings.HasPrefix(arg, xprefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// debundle splits a bundle of short options into single-character options.
+func (sx *Scanner) debundle(idx int, prefix, name string) []OptionToken {
+	var options []OptionToken
+	for sub := 0; name != ""; sub++ {
+		_, size := utf8.DecodeRuneInString(name)
+		tk := OptionToken{Idx: idx, SubIdx: sub, Prefix: prefix, Name: name[:size]}
+		name = name[size:]
+
+		// The negation suffix negates the preceding option
+		if sx.NegationSuffix != "" && strings.HasPrefix(name, sx.NegationSuffix) {
+			tk.Negated, tk.NegationSuffix = true, sx.NegationSuffix
+			name = name[len(sx.NegationSuffix):]
+		}
+
+		// A value separator assigns the rest of the bundle to the preceding option
+		if sx.SplitValues {
+			if pos, sep := indexAny(name, sx.valueSeparators()); pos == 0 {
+				tk.ValueSeparator, tk.Value = sep, name[len(sep):]
+				name = ""
+			}
+		}
+
+		// An option with values takes the rest of the bundle as its value
+		if name != "" && sx.OptionsWithArity[tk.Name] > 0 {
+			tk.Value = name
+			name = ""
+		}
+
+		options = append(options, tk)
+	}
+	return options
+}
+
+// newOptionToken creates a new [OptionToken] splitting the value, if any.
+func (sx *Scanner) newOptionToken(idx int, prefix, name string) OptionToken {
+	tk := OptionToken{Idx: idx, Prefix: prefix, Name: name}
+	if sx.SplitValues {
+		if pos, sep := indexAny(name, sx.valueSeparators()); pos >= 0 {
+			tk.Name, tk.ValueSeparator, tk.Value = name[:pos], sep, name[pos+len(sep):]
+			sx.splitDeclaredType(&tk)
+			return tk
+		}
+	}
+	if len(prefix) > 1 {
+		if known := longestKnownPrefix(name, sx.LongOptionsWithAttachedValue); known != "" {
+			tk.Name, tk.Value = known, name[len(known):]
+		}
+	}
+	sx.splitDeclaredType(&tk)
+	return tk
+}
+
+// splitDeclaredType moves the type annotation following the
+// name into the DeclaredType when using [Scanner.InlineTypeAnnotations].
+func (sx *Scanner) splitDeclaredType(tk *OptionToken) {
+	if !sx.InlineTypeAnnotations {
+		return
+	}
+	if name, declared, found := strings.Cut(tk.Name, ":"); found && name != "" && declared != "" {
+		tk.Name, tk.DeclaredType = name, declared
+	}
+}
+
+// longestKnownPrefix returns the longest name in known that is a prefix of s.
+func longestKnownPrefix(s string, known map[string]bool) string {
+	var longest string
+	for name, ok := range known {
+		if ok && len(name) > len(longest) && strings.HasPrefix(s, name) {
+			longest = name
+		}
+	}
+	return longest
+}
+
+// consumeValues sets the option values that follow the option in args,
+// if needed, and returns the index of the last consumed argument.
+func (sx *Scanner) consumeValues(tk *OptionToken, args []string, idx int) int {
+	switch arity := sx.OptionsWithArity[tk.Name]; {
+	case sx.TreatTrailingEqualsAsPending && tk.ValueSeparator != "" && tk.Value == "":
+		if idx+1 >= len(args) {
+			tk.MissingValue = true
+			break
+		}
+		idx++
+		tk.Value, tk.Consumed = args[idx], 1
+
+	case arity > 0 && !tk.hasValue():
+		if idx+arity >= len(args) {
+			tk.MissingValue = true
+			break
+		}
+		values := args[idx+1 : idx+1+arity]
+		if arity == 1 {
+			tk.Value = values[0]
+		} else {
+			tk.ValueList = slices.Clone(values)
+		}
+		tk.Consumed = arity
+		idx += arity
+	}
+
+	if delim := sx.ValueListDelimiter[tk.Name]; sx.SplitValues && delim != "" && tk.hasValue() {
+		tk.ValueList = strings.Split(tk.Value, delim)
+	}
+	return idx
+}
+
+// valueSeparators returns the configured value separators or the default.
+func (sx *Scanner) valueSeparators() []string {
+	if len(sx.ValueSeparators) <= 0 {
+		return []string{"="}
+	}
+	return sx.ValueSeparators
+}
+
+// indexAny returns the position of the first occurrence of any nonempty
+// needle in s along with such needle, or -1 if there is no occurrence.
+func indexAny(s string, needles []string) (int, string) {
+	pos, found := -1, ""
+	for _, needle := range needles {
+		if needle == "" {
+			continue
+		}
+		if p := strings.Index(s, needle); p >= 0 && (pos < 0 || p < pos) {
+			pos, found = p, needle
+		}
+	}
+	return pos, found
+}
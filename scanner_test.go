@@ -3,7 +3,13 @@
 
 package flagscanner
 
-import "testing"
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
 
 // This test ensures that the [Token.Index] method is working as
 // intended for each available token type.
@@ -28,6 +34,31 @@ func TestTokenIndex(t *testing.T) {
 			token:    OptionsArgumentsSeparatorToken{Idx: 1},
 			expected: 1,
 		},
+		{
+			name:     "RawRemainderToken",
+			token:    RawRemainderToken{Idx: 1},
+			expected: 1,
+		},
+		{
+			name:     "SubcommandToken",
+			token:    SubcommandToken{Idx: 1},
+			expected: 1,
+		},
+		{
+			name:     "AssignmentToken",
+			token:    AssignmentToken{Idx: 1},
+			expected: 1,
+		},
+		{
+			name:     "LiteralNextToken",
+			token:    LiteralNextToken{Idx: 1},
+			expected: 1,
+		},
+		{
+			name:     "EndToken",
+			token:    EndToken{Idx: 1},
+			expected: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -68,6 +99,36 @@ func TestTokenString(t *testing.T) {
 			token:    OptionsArgumentsSeparatorToken{Separator: "--"},
 			expected: "--",
 		},
+		{
+			name:     "RawRemainderToken",
+			token:    RawRemainderToken{Args: []string{"ls", "-la"}},
+			expected: "ls -la",
+		},
+		{
+			name:     "SubcommandToken",
+			token:    SubcommandToken{Name: "exec"},
+			expected: "exec",
+		},
+		{
+			name:     "OptionToken with consumed value",
+			token:    OptionToken{Prefix: "--", Name: "file", Value: "x", Consumed: 1},
+			expected: "--file",
+		},
+		{
+			name:     "AssignmentToken",
+			token:    AssignmentToken{Key: "FOO", Separator: "=", Value: "bar"},
+			expected: "FOO=bar",
+		},
+		{
+			name:     "LiteralNextToken",
+			token:    LiteralNextToken{Marker: "++noopt"},
+			expected: "++noopt",
+		},
+		{
+			name:     "EndToken",
+			token:    EndToken{Idx: 1},
+			expected: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -80,6 +141,54 @@ func TestTokenString(t *testing.T) {
 	}
 }
 
+// This test ensures that [OptionToken.ContainsValueSeparator] detects
+// the value separators in the name without splitting it.
+func TestOptionTokenContainsValueSeparator(t *testing.T) {
+	tests := []struct {
+		name       string
+		arg        string
+		separators []string
+		expected   bool
+	}{
+		{
+			name:     "default separator",
+			arg:      "--file=config.txt",
+			expected: true,
+		},
+		{
+			name:     "no separator",
+			arg:      "--verbose",
+			expected: false,
+		},
+		{
+			name:       "colon separator",
+			arg:        "--out:dir",
+			separators: []string{":"},
+			expected:   true,
+		},
+		{
+			name:       "equals without being configured",
+			arg:        "--file=config.txt",
+			separators: []string{":"},
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{Prefixes: []string{"-", "--"}}
+			tokens := scanner.Scan([]string{tt.arg})
+			tk := tokens[0].(OptionToken)
+			if got := tk.ContainsValueSeparator(tt.separators...); got != tt.expected {
+				t.Errorf("ContainsValueSeparator(%q) = %v, want %v", tt.separators, got, tt.expected)
+			}
+			if tk.String() != tt.arg {
+				t.Errorf("String() = %q, want the unmodified %q", tk.String(), tt.arg)
+			}
+		})
+	}
+}
+
 // This test ensures that we can use `-` to indicate stdout and it is
 // recognized as a positional argument rather than as a flag.
 func TestScannerZeroLengthOption(t *testing.T) {
@@ -124,3 +233,2495 @@ func TestScannerSeparatorStopsParsing(t *testing.T) {
 		}
 	}
 }
+
+// This test ensures that [Scanner.EscapeByDoublingPrefix] turns a doubled
+// prefix into a positional argument containing the prefix.
+func TestScannerEscapeByDoublingPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		escape   bool
+		args     []string
+		expected []Token
+	}{
+		{
+			name:   "doubled prefix with escaping",
+			escape: true,
+			args:   []string{"++"},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "+", RawValue: "++"},
+			},
+		},
+		{
+			name:   "option with escaping",
+			escape: true,
+			args:   []string{"+x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "+", Name: "x"},
+			},
+		},
+		{
+			name:   "doubled prefix without escaping",
+			escape: false,
+			args:   []string{"++"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "+", Name: "+"},
+			},
+		},
+		{
+			name:   "separator takes precedence",
+			escape: true,
+			args:   []string{"--", "--"},
+			expected: []Token{
+				OptionsArgumentsSeparatorToken{Idx: 0, Separator: "--"},
+				PositionalArgumentToken{Idx: 1, Value: "--"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:               []string{"-", "+"},
+				Separator:              "--",
+				EscapeByDoublingPrefix: tt.escape,
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.LongOptionsWithAttachedValue] splits
+// the attached value using the longest listed name.
+func TestScannerLongOptionsWithAttachedValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "attached value",
+			args: []string{"--port8080"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "port", Value: "8080"},
+			},
+		},
+		{
+			name: "no value",
+			args: []string{"--port"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "port"},
+			},
+		},
+		{
+			name: "ambiguous overlap uses the longest name",
+			args: []string{"--portal80", "--port80"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "portal", Value: "80"},
+				OptionToken{Idx: 1, Prefix: "--", Name: "port", Value: "80"},
+			},
+		},
+		{
+			name: "short options are not affected",
+			args: []string{"-port8080"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "port8080"},
+			},
+		},
+		{
+			name: "unlisted options are not affected",
+			args: []string{"--verbose"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "verbose"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:  []string{"-", "--"},
+				Separator: "--",
+				LongOptionsWithAttachedValue: map[string]bool{
+					"port":   true,
+					"portal": true,
+				},
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+			for idx, token := range got {
+				if token.String() != tt.args[idx] {
+					t.Errorf("Token.String() = %q, want %q", token.String(), tt.args[idx])
+				}
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.SplitValues] splits option names
+// at the first configured value separator.
+func TestScannerSplitValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		separators []string
+		args       []string
+		expected   []Token
+	}{
+		{
+			name: "default separator",
+			args: []string{"--file=x", "-v"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", ValueSeparator: "=", Value: "x"},
+				OptionToken{Idx: 1, Prefix: "-", Name: "v"},
+			},
+		},
+		{
+			name:       "first occurrence among many separators",
+			separators: []string{"=", ":"},
+			args:       []string{"--a:b=c"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "a", ValueSeparator: ":", Value: "b=c"},
+			},
+		},
+		{
+			name: "empty value",
+			args: []string{"--file="},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", ValueSeparator: "="},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:        []string{"-", "--"},
+				SplitValues:     true,
+				ValueSeparators: tt.separators,
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+			for idx, token := range got {
+				if token.String() != tt.args[idx] {
+					t.Errorf("Token.String() = %q, want %q", token.String(), tt.args[idx])
+				}
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.TreatTrailingEqualsAsPending] takes
+// the value of an option ending with "=" from the next argument.
+func TestScannerTreatTrailingEqualsAsPending(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "value in the next argument",
+			args: []string{"--file=", "x", "y"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", ValueSeparator: "=", Value: "x", Consumed: 1},
+				PositionalArgumentToken{Idx: 2, Value: "y"},
+			},
+		},
+		{
+			name: "no next argument",
+			args: []string{"--file="},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", ValueSeparator: "=", MissingValue: true},
+			},
+		},
+		{
+			name: "immediate value",
+			args: []string{"--file=x", "y"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", ValueSeparator: "=", Value: "x"},
+				PositionalArgumentToken{Idx: 1, Value: "y"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:                     []string{"-", "--"},
+				SplitValues:                  true,
+				TreatTrailingEqualsAsPending: true,
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.ValueListDelimiter] splits the value
+// of the configured options into a list.
+func TestScannerValueListDelimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "comma separated list",
+			args: []string{"--include=a,b,c"},
+			expected: []Token{
+				OptionToken{
+					Idx:            0,
+					Prefix:         "--",
+					Name:           "include",
+					ValueSeparator: "=",
+					Value:          "a,b,c",
+					ValueList:      []string{"a", "b", "c"},
+				},
+			},
+		},
+		{
+			name: "single value",
+			args: []string{"--include=a"},
+			expected: []Token{
+				OptionToken{
+					Idx:            0,
+					Prefix:         "--",
+					Name:           "include",
+					ValueSeparator: "=",
+					Value:          "a",
+					ValueList:      []string{"a"},
+				},
+			},
+		},
+		{
+			name: "option without delimiter",
+			args: []string{"--exclude=a,b"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "exclude", ValueSeparator: "=", Value: "a,b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:           []string{"-", "--"},
+				SplitValues:        true,
+				ValueListDelimiter: map[string]string{"include": ","},
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that a single-character separator equal to a prefix
+// takes precedence over the rule turning a bare prefix into a positional.
+func TestScannerSingleDashSeparator(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:  []string{"-", "--"},
+		Separator: "-",
+	}
+
+	got := scanner.Scan([]string{"-v", "-", "-x", "--y", "-"})
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+		OptionsArgumentsSeparatorToken{Idx: 1, Separator: "-"},
+		PositionalArgumentToken{Idx: 2, Value: "-x"},
+		PositionalArgumentToken{Idx: 3, Value: "--y"},
+		PositionalArgumentToken{Idx: 4, Value: "-"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [Scanner.PositionalNames] names the positional
+// arguments preceding the separator in order.
+func TestScannerPositionalNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "exactly matching count",
+			args: []string{"a", "-v", "b"},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "a", Name: "SOURCE"},
+				OptionToken{Idx: 1, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 2, Value: "b", Name: "DEST"},
+			},
+		},
+		{
+			name: "fewer operands than names",
+			args: []string{"a"},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "a", Name: "SOURCE"},
+			},
+		},
+		{
+			name: "more operands than names",
+			args: []string{"a", "b", "c", "--", "d"},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "a", Name: "SOURCE"},
+				PositionalArgumentToken{Idx: 1, Value: "b", Name: "DEST"},
+				PositionalArgumentToken{Idx: 2, Value: "c"},
+				OptionsArgumentsSeparatorToken{Idx: 3, Separator: "--"},
+				PositionalArgumentToken{Idx: 4, Value: "d"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:        []string{"-", "--"},
+				Separator:       "--",
+				PositionalNames: []string{"SOURCE", "DEST"},
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.Canonicalize] sets the canonical
+// name while leaving the original name unchanged.
+func TestScannerCanonicalize(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:    []string{"-", "--"},
+		SplitValues: true,
+		Canonicalize: func(name string) string {
+			return strings.ReplaceAll(name, "-", "_")
+		},
+	}
+
+	got := scanner.Scan([]string{"--dry-run", "--log-level=debug", "file-name"})
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "--", Name: "dry-run", CanonicalName: "dry_run"},
+		OptionToken{
+			Idx:            1,
+			Prefix:         "--",
+			Name:           "log-level",
+			ValueSeparator: "=",
+			Value:          "debug",
+			CanonicalName:  "log_level",
+		},
+		PositionalArgumentToken{Idx: 2, Value: "file-name"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [Scanner.FoldName] sets the FoldedName
+// while preserving the original Name.
+func TestScannerFoldName(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes: []string{"-", "--"},
+		FoldName: func(name string) string {
+			return strings.ToLower(name)
+		},
+	}
+
+	got := scanner.Scan([]string{"--FILE", "--file", "--Input", "FILE"})
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "--", Name: "FILE", FoldedName: "file"},
+		OptionToken{Idx: 1, Prefix: "--", Name: "file", FoldedName: "file"},
+		OptionToken{Idx: 2, Prefix: "--", Name: "Input", FoldedName: "input"},
+		PositionalArgumentToken{Idx: 3, Value: "FILE"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [Scanner.PrefixRequiresNonDigit] routes
+// arguments starting with a digit to positional arguments.
+func TestScannerPrefixRequiresNonDigit(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:               []string{"-", "+"},
+		PrefixRequiresNonDigit: map[string]bool{"+": true},
+	}
+
+	got := scanner.Scan([]string{"+1234", "+trace", "-1"})
+
+	expect := []Token{
+		PositionalArgumentToken{Idx: 0, Value: "+1234"},
+		OptionToken{Idx: 1, Prefix: "+", Name: "trace"},
+		OptionToken{Idx: 2, Prefix: "-", Name: "1"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [Scanner.TerminatingOptions] and
+// [Scanner.CaptureRemainderAsRaw] capture the remaining arguments.
+func TestScannerCaptureRemainder(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      bool
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "raw remainder after separator",
+			raw:  true,
+			args: []string{"exec", "--", "ls", "-la", "/"},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "exec"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				RawRemainderToken{Idx: 2, Args: []string{"ls", "-la", "/"}},
+			},
+		},
+		{
+			name: "raw remainder after terminating option",
+			raw:  true,
+			args: []string{"-v", "-e", "ls", "-la"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 1, Prefix: "-", Name: "e"},
+				RawRemainderToken{Idx: 2, Args: []string{"ls", "-la"}},
+			},
+		},
+		{
+			name: "positional remainder after terminating option",
+			raw:  false,
+			args: []string{"-e", "ls", "-la"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "e"},
+				PositionalArgumentToken{Idx: 1, Value: "ls"},
+				PositionalArgumentToken{Idx: 2, Value: "-la"},
+			},
+		},
+		{
+			name: "empty remainder",
+			raw:  true,
+			args: []string{"-e"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "e"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:              []string{"-", "--"},
+				Separator:             "--",
+				TerminatingOptions:    map[string]bool{"e": true},
+				CaptureRemainderAsRaw: tt.raw,
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.BundleShortOptions] splits bundles
+// of short options and assigns values to options with arity.
+func TestScannerBundleShortOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "flags bundle",
+			args: []string{"-abc"},
+			expected: []Token{
+				OptionToken{Idx: 0, SubIdx: 0, Prefix: "-", Name: "a"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "b"},
+				OptionToken{Idx: 0, SubIdx: 2, Prefix: "-", Name: "c"},
+			},
+		},
+		{
+			name: "attached value",
+			args: []string{"-vffile", "x"},
+			expected: []Token{
+				OptionToken{Idx: 0, SubIdx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "f", Value: "file"},
+				PositionalArgumentToken{Idx: 1, Value: "x"},
+			},
+		},
+		{
+			name: "value in the next argument",
+			args: []string{"-vf", "file", "x"},
+			expected: []Token{
+				OptionToken{Idx: 0, SubIdx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "f", Value: "file", Consumed: 1},
+				PositionalArgumentToken{Idx: 2, Value: "x"},
+			},
+		},
+		{
+			name: "long options are not bundles",
+			args: []string{"--verbose"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "verbose"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:           []string{"-", "--"},
+				Separator:          "--",
+				BundleShortOptions: true,
+				OptionsWithArity:   map[string]int{"f": 1},
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.NegationSuffix] negates short options,
+// including options within bundles.
+func TestScannerNegationSuffix(t *testing.T) {
+	tests := []struct {
+		name     string
+		bundle   bool
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "negated",
+			args: []string{"-x-"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "x", Negated: true, NegationSuffix: "-"},
+			},
+		},
+		{
+			name: "not negated",
+			args: []string{"-x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "x"},
+			},
+		},
+		{
+			name:   "negated within a bundle",
+			bundle: true,
+			args:   []string{"-x-y"},
+			expected: []Token{
+				OptionToken{Idx: 0, SubIdx: 0, Prefix: "-", Name: "x", Negated: true, NegationSuffix: "-"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "y"},
+			},
+		},
+		{
+			name: "long options are not negated",
+			args: []string{"--x-"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "x-"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:           []string{"-", "--"},
+				BundleShortOptions: tt.bundle,
+				NegationSuffix:     "-",
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that, when bundling and splitting values, the
+// last option of a bundle takes the value following the separator.
+func TestScannerBundleWithValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		split    bool
+		args     []string
+		expected []Token
+	}{
+		{
+			name:  "last bundled option takes the value",
+			split: true,
+			args:  []string{"-abc=d", "e"},
+			expected: []Token{
+				OptionToken{Idx: 0, SubIdx: 0, Prefix: "-", Name: "a"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "b"},
+				OptionToken{Idx: 0, SubIdx: 2, Prefix: "-", Name: "c", ValueSeparator: "=", Value: "d"},
+				PositionalArgumentToken{Idx: 1, Value: "e"},
+			},
+		},
+		{
+			name:  "single option with value",
+			split: true,
+			args:  []string{"-a=b"},
+			expected: []Token{
+				OptionToken{Idx: 0, SubIdx: 0, Prefix: "-", Name: "a", ValueSeparator: "=", Value: "b"},
+			},
+		},
+		{
+			name:  "without splitting values",
+			split: false,
+			args:  []string{"-a=b"},
+			expected: []Token{
+				OptionToken{Idx: 0, SubIdx: 0, Prefix: "-", Name: "a"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "="},
+				OptionToken{Idx: 0, SubIdx: 2, Prefix: "-", Name: "b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:           []string{"-", "--"},
+				BundleShortOptions: true,
+				SplitValues:        tt.split,
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.EmitEndToken] appends exactly one
+// [EndToken] with the correct index and that it is absent by default.
+func TestScannerEmitEndToken(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "empty",
+			args: []string{},
+		},
+		{
+			name: "options and positionals",
+			args: []string{"-v", "file.txt"},
+		},
+		{
+			name: "with separator",
+			args: []string{"-v", "--", "-x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:     []string{"-"},
+				Separator:    "--",
+				EmitEndToken: true,
+			}
+			got := scanner.Scan(tt.args)
+			var count int
+			for _, token := range got {
+				if _, ok := token.(EndToken); ok {
+					count++
+				}
+			}
+			if count != 1 {
+				t.Fatalf("Expected 1 EndToken, got %d", count)
+			}
+			last := got[len(got)-1]
+			if last != (EndToken{Idx: len(tt.args)}) {
+				t.Errorf("Expected EndToken{Idx: %d} at the end, got %#v", len(tt.args), last)
+			}
+
+			scanner.EmitEndToken = false
+			for _, token := range scanner.Scan(tt.args) {
+				if _, ok := token.(EndToken); ok {
+					t.Errorf("Unexpected EndToken by default")
+				}
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.StopAtSubcommand] stops parsing
+// options after a known subcommand.
+func TestScannerStopAtSubcommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		known    map[string]bool
+		expected []Token
+	}{
+		{
+			name:  "known subcommand",
+			known: map[string]bool{"build": true},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "build"},
+				PositionalArgumentToken{Idx: 2, Value: "--inner"},
+			},
+		},
+		{
+			name:  "unknown subcommand",
+			known: map[string]bool{"run": true},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "build"},
+				OptionToken{Idx: 2, Prefix: "--", Name: "inner"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:         []string{"-", "--"},
+				Separator:        "--",
+				StopAtSubcommand: true,
+				KnownSubcommands: tt.known,
+			}
+			args := []string{"-v", "build", "--inner"}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.RecordPrefixConfigIndex] records
+// the position of the prefix in the original configuration.
+func TestScannerRecordPrefixConfigIndex(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:                []string{"+", "-", "--"},
+		RecordPrefixConfigIndex: true,
+	}
+
+	got := scanner.Scan([]string{"--verbose", "-v", "+trace"})
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "--", PrefixConfigIndex: 2, Name: "verbose"},
+		OptionToken{Idx: 1, Prefix: "-", PrefixConfigIndex: 1, Name: "v"},
+		OptionToken{Idx: 2, Prefix: "+", PrefixConfigIndex: 0, Name: "trace"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [Scanner.RecordPrefixConfigIndex] records the prefix
+// preceding the [Scanner.WExtensionOption] and -1 for switched prefixes.
+func TestScannerRecordPrefixConfigIndexSpecialCases(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:                []string{"+", "-"},
+		RecordPrefixConfigIndex: true,
+		WExtensionOption:        "W",
+		PrefixSwitchOptions:     map[string][]string{"legacy": {"/"}},
+	}
+	args := []string{"-Wverbose", "-legacy", "/v"}
+
+	got := scanner.Scan(args)
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "--", PrefixConfigIndex: 1, Name: "verbose", Extension: "-W"},
+		OptionToken{Idx: 1, Prefix: "-", PrefixConfigIndex: 1, Name: "legacy"},
+		OptionToken{Idx: 2, Prefix: "/", PrefixConfigIndex: -1, Name: "v"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan(%q) = %#v, want %#v", args, got, expect)
+	}
+}
+
+// This test ensures that [Scanner.SeparatorIntroducesSubcommand] emits
+// the first argument after the separator as a [SubcommandToken].
+func TestScannerSeparatorIntroducesSubcommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected []Token
+	}{
+		{
+			name:    "enabled",
+			enabled: true,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				SubcommandToken{Idx: 2, Name: "exec"},
+				PositionalArgumentToken{Idx: 3, Value: "a"},
+				PositionalArgumentToken{Idx: 4, Value: "b"},
+			},
+		},
+		{
+			name:    "disabled by default",
+			enabled: false,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				PositionalArgumentToken{Idx: 2, Value: "exec"},
+				PositionalArgumentToken{Idx: 3, Value: "a"},
+				PositionalArgumentToken{Idx: 4, Value: "b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:                      []string{"-"},
+				Separator:                     "--",
+				SeparatorIntroducesSubcommand: tt.enabled,
+			}
+			args := []string{"-v", "--", "exec", "a", "b"}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.ToggleOnSeparator] makes each
+// separator flip between option parsing and positional arguments.
+func TestScannerToggleOnSeparator(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected []Token
+	}{
+		{
+			name:    "enabled",
+			enabled: true,
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "a"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				PositionalArgumentToken{Idx: 2, Value: "b"},
+				PositionalArgumentToken{Idx: 3, Value: "-v"},
+				OptionsArgumentsSeparatorToken{Idx: 4, Separator: "--"},
+				OptionToken{Idx: 5, Prefix: "-", Name: "c"},
+			},
+		},
+		{
+			name:    "disabled by default",
+			enabled: false,
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "a"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				PositionalArgumentToken{Idx: 2, Value: "b"},
+				PositionalArgumentToken{Idx: 3, Value: "-v"},
+				PositionalArgumentToken{Idx: 4, Value: "--"},
+				PositionalArgumentToken{Idx: 5, Value: "-c"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:          []string{"-"},
+				Separator:         "--",
+				ToggleOnSeparator: tt.enabled,
+			}
+			args := []string{"a", "--", "b", "-v", "--", "-c"}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.CountableFlags] counts repeated
+// options and distinguishes "-vv" from "-v -v".
+func TestScannerCountableFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "bundle",
+			args: []string{"-vv"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v", Count: 2, FromBundle: true},
+			},
+		},
+		{
+			name: "repeated",
+			args: []string{"-v", "-v"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v", Count: 1},
+				OptionToken{Idx: 1, Prefix: "-", Name: "v", Count: 1},
+			},
+		},
+		{
+			name: "mixed bundle is debundled",
+			args: []string{"-vx"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v", Count: 1},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "x"},
+			},
+		},
+		{
+			name: "long prefix is not counted",
+			args: []string{"--vv"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "vv"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:           []string{"-", "--"},
+				Separator:          "--",
+				BundleShortOptions: true,
+				CountableFlags:     map[string]bool{"v": true},
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+			if joined := Join(got); !reflect.DeepEqual(joined, tt.args) {
+				t.Errorf("Join() = %q, want %q", joined, tt.args)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.EmptyArgIsSeparator] makes an empty
+// argument act as the separator and that [Scanner.SkipEmptyArguments]
+// drops the empty arguments preceding the separator.
+func TestScannerEmptyArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		separator bool
+		skip      bool
+		expected  []Token
+	}{
+		{
+			name:      "empty arg is separator",
+			separator: true,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: ""},
+				PositionalArgumentToken{Idx: 2, Value: "-x"},
+				PositionalArgumentToken{Idx: 3, Value: ""},
+			},
+		},
+		{
+			name: "disabled by default",
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: ""},
+				OptionToken{Idx: 2, Prefix: "-", Name: "x"},
+				PositionalArgumentToken{Idx: 3, Value: ""},
+			},
+		},
+		{
+			name: "skip empty arguments",
+			skip: true,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 2, Prefix: "-", Name: "x"},
+			},
+		},
+		{
+			name:      "separator takes precedence over skipping",
+			separator: true,
+			skip:      true,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: ""},
+				PositionalArgumentToken{Idx: 2, Value: "-x"},
+				PositionalArgumentToken{Idx: 3, Value: ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:            []string{"-"},
+				Separator:           "--",
+				EmptyArgIsSeparator: tt.separator,
+				SkipEmptyArguments:  tt.skip,
+			}
+			args := []string{"-v", "", "-x", ""}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.CommentPrefix] drops the
+// argument starting a comment and all the following arguments.
+func TestScannerCommentPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		args     []string
+		expected []Token
+	}{
+		{
+			name:   "comment mid stream",
+			prefix: "#",
+			args:   []string{"-v", "file", "#", "-x", "other"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "file"},
+			},
+		},
+		{
+			name:   "comment attached to the text",
+			prefix: "#",
+			args:   []string{"-v", "#-x", "other"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+			},
+		},
+		{
+			name:   "comment after the separator",
+			prefix: "#",
+			args:   []string{"-v", "--", "#", "-x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				PositionalArgumentToken{Idx: 2, Value: "#"},
+				PositionalArgumentToken{Idx: 3, Value: "-x"},
+			},
+		},
+		{
+			name: "disabled by default",
+			args: []string{"-v", "#", "-x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "#"},
+				OptionToken{Idx: 2, Prefix: "-", Name: "x"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:      []string{"-"},
+				Separator:     "--",
+				CommentPrefix: tt.prefix,
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.WExtensionOption] turns the
+// following name, either attached or separate, into a long option.
+func TestScannerWExtensionOption(t *testing.T) {
+	tests := []struct {
+		name      string
+		extension string
+		args      []string
+		expected  []Token
+	}{
+		{
+			name:      "separate name",
+			extension: "W",
+			args:      []string{"-W", "verbose", "file"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "verbose", Extension: "-W", NameConsumed: 1},
+				PositionalArgumentToken{Idx: 2, Value: "file"},
+			},
+		},
+		{
+			name:      "attached name",
+			extension: "W",
+			args:      []string{"-Wverbose", "file"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "verbose", Extension: "-W"},
+				PositionalArgumentToken{Idx: 1, Value: "file"},
+			},
+		},
+		{
+			name:      "attached name with value",
+			extension: "W",
+			args:      []string{"-Wfile=x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", ValueSeparator: "=", Value: "x", Extension: "-W"},
+			},
+		},
+		{
+			name:      "extension option alone",
+			extension: "W",
+			args:      []string{"-v", "-W"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 1, Prefix: "-", Name: "W"},
+			},
+		},
+		{
+			name:      "extension option followed by the separator",
+			extension: "W",
+			args:      []string{"-W", "--", "x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "W"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				PositionalArgumentToken{Idx: 2, Value: "x"},
+			},
+		},
+		{
+			name: "disabled by default",
+			args: []string{"-W", "verbose"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "W"},
+				PositionalArgumentToken{Idx: 1, Value: "verbose"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:         []string{"-", "--"},
+				Separator:        "--",
+				SplitValues:      true,
+				WExtensionOption: tt.extension,
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.DisableOptionParsing] makes every
+// argument positional, possibly except the separator.
+func TestScannerDisableOptionParsing(t *testing.T) {
+	tests := []struct {
+		name          string
+		disable       bool
+		keepSeparator bool
+		expected      []Token
+	}{
+		{
+			name:    "everything is positional",
+			disable: true,
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "-v", Name: "first"},
+				PositionalArgumentToken{Idx: 1, Value: "--file=x", Name: "second"},
+				PositionalArgumentToken{Idx: 2, Value: "--"},
+				PositionalArgumentToken{Idx: 3, Value: "-x"},
+			},
+		},
+		{
+			name:          "keeping the separator",
+			disable:       true,
+			keepSeparator: true,
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "-v", Name: "first"},
+				PositionalArgumentToken{Idx: 1, Value: "--file=x", Name: "second"},
+				OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+				PositionalArgumentToken{Idx: 3, Value: "-x"},
+			},
+		},
+		{
+			name: "option parsing enabled",
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 1, Prefix: "--", Name: "file=x"},
+				OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+				PositionalArgumentToken{Idx: 3, Value: "-x"},
+			},
+		},
+	}
+
+	shared := &Scanner{
+		Prefixes:        []string{"-", "--"},
+		Separator:       "--",
+		PositionalNames: []string{"first", "second"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := shared.Clone()
+			scanner.DisableOptionParsing = tt.disable
+			scanner.DisableOptionParsingKeepsSeparator = tt.keepSeparator
+			args := []string{"-v", "--file=x", "--", "-x"}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.TreatWhitespaceAsEmpty] interacts
+// with [Scanner.SkipEmptyArguments] as documented.
+func TestScannerTreatWhitespaceAsEmpty(t *testing.T) {
+	tests := []struct {
+		name       string
+		whitespace bool
+		skip       bool
+		expected   []Token
+	}{
+		{
+			name: "neither",
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "   "},
+				PositionalArgumentToken{Idx: 2, Value: "x"},
+			},
+		},
+		{
+			name: "skip empty arguments only",
+			skip: true,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "   "},
+				PositionalArgumentToken{Idx: 2, Value: "x"},
+			},
+		},
+		{
+			name:       "treat whitespace as empty only",
+			whitespace: true,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "", RawValue: "   "},
+				PositionalArgumentToken{Idx: 2, Value: "x"},
+			},
+		},
+		{
+			name:       "both",
+			whitespace: true,
+			skip:       true,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 2, Value: "x"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:               []string{"-"},
+				Separator:              "--",
+				TreatWhitespaceAsEmpty: tt.whitespace,
+				SkipEmptyArguments:     tt.skip,
+			}
+			args := []string{"-v", "   ", "x"}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.InlineTypeAnnotations] splits
+// the type annotation from the option name.
+func TestScannerInlineTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations bool
+		args        []string
+		expected    []Token
+	}{
+		{
+			name:        "integer annotation",
+			annotations: true,
+			args:        []string{"--count:int=5"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "count", DeclaredType: "int", ValueSeparator: "=", Value: "5"},
+			},
+		},
+		{
+			name:        "string annotation",
+			annotations: true,
+			args:        []string{"--name:string=foo"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "name", DeclaredType: "string", ValueSeparator: "=", Value: "foo"},
+			},
+		},
+		{
+			name:        "annotation with consumed value",
+			annotations: true,
+			args:        []string{"--file:path", "x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", DeclaredType: "path", Value: "x", Consumed: 1},
+			},
+		},
+		{
+			name:        "no annotation",
+			annotations: true,
+			args:        []string{"--flag", "--empty:"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "flag"},
+				OptionToken{Idx: 1, Prefix: "--", Name: "empty:"},
+			},
+		},
+		{
+			name: "disabled by default",
+			args: []string{"--count:int=5"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "count:int", ValueSeparator: "=", Value: "5"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:              []string{"-", "--"},
+				Separator:             "--",
+				SplitValues:           true,
+				OptionsWithArity:      map[string]int{"file": 1},
+				InlineTypeAnnotations: tt.annotations,
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+			if joined := Join(got); !reflect.DeepEqual(joined, tt.args) {
+				t.Errorf("Join() = %q, want %q", joined, tt.args)
+			}
+		})
+	}
+}
+
+// This test ensures that empty [Scanner.Prefixes] and [Scanner.PositionalOnly]
+// make every argument positional except the separator.
+func TestScannerPositionalOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		scanner  *Scanner
+		expected []Token
+	}{
+		{
+			name:    "empty prefixes",
+			scanner: &Scanner{Separator: "--"},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "-v"},
+				PositionalArgumentToken{Idx: 1, Value: "++"},
+				OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+				PositionalArgumentToken{Idx: 3, Value: "--file"},
+			},
+		},
+		{
+			name: "positional only with prefixes",
+			scanner: &Scanner{
+				Prefixes:               []string{"-", "--", "+"},
+				Separator:              "--",
+				BundleShortOptions:     true,
+				EscapeByDoublingPrefix: true,
+				ErrorOnPrefixOnly:      true,
+				PositionalOnly:         true,
+			},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "-v"},
+				PositionalArgumentToken{Idx: 1, Value: "++"},
+				OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+				PositionalArgumentToken{Idx: 3, Value: "--file"},
+			},
+		},
+		{
+			name: "prefixes without positional only",
+			scanner: &Scanner{
+				Prefixes:  []string{"-", "--", "+"},
+				Separator: "--",
+			},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 1, Prefix: "+", Name: "+"},
+				OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+				PositionalArgumentToken{Idx: 3, Value: "--file"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []string{"-v", "++", "--", "--file"}
+			got, err := tt.scanner.ScanStrict(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.RecordPositionalIndex] numbers
+// the positional arguments regardless of their Idx.
+func TestScannerRecordPositionalIndex(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:              []string{"-", "--"},
+		Separator:             "--",
+		OptionsWithArity:      map[string]int{"file": 1},
+		RecordPositionalIndex: true,
+	}
+	args := []string{"-v", "a", "--file", "x", "b", "-y", "--", "c"}
+	got := scanner.Scan(args)
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+		PositionalArgumentToken{Idx: 1, Value: "a", PositionalIndex: 0},
+		OptionToken{Idx: 2, Prefix: "--", Name: "file", Value: "x", Consumed: 1},
+		PositionalArgumentToken{Idx: 4, Value: "b", PositionalIndex: 1},
+		OptionToken{Idx: 5, Prefix: "-", Name: "y"},
+		OptionsArgumentsSeparatorToken{Idx: 6, Separator: "--"},
+		PositionalArgumentToken{Idx: 7, Value: "c", PositionalIndex: 2},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan(%q) = %#v, want %#v", args, got, expect)
+	}
+
+	// The index persists across batches
+	stream := scanner.NewStreamScanner()
+	stream.ScanBatch([]string{"a", "-v"})
+	batch := stream.ScanBatch([]string{"b"})
+	if len(batch) != 1 || batch[0].(PositionalArgumentToken).PositionalIndex != 1 {
+		t.Errorf("ScanBatch() = %#v, want PositionalIndex 1", batch)
+	}
+}
+
+// This test ensures that [Scanner.AfterSeparatorEscapes] emits the listed
+// arguments following the separator as options.
+func TestScannerAfterSeparatorEscapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		escapes  []string
+		expected []Token
+	}{
+		{
+			name:    "escape after the separator",
+			escapes: []string{"--meta", "+trace"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				PositionalArgumentToken{Idx: 2, Value: "-x"},
+				OptionToken{Idx: 3, Prefix: "--", Name: "meta"},
+				PositionalArgumentToken{Idx: 4, Value: "--meta=1"},
+				PositionalArgumentToken{Idx: 5, Value: "file"},
+			},
+		},
+		{
+			name: "disabled by default",
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				PositionalArgumentToken{Idx: 2, Value: "-x"},
+				PositionalArgumentToken{Idx: 3, Value: "--meta"},
+				PositionalArgumentToken{Idx: 4, Value: "--meta=1"},
+				PositionalArgumentToken{Idx: 5, Value: "file"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:              []string{"-", "--"},
+				Separator:             "--",
+				AfterSeparatorEscapes: tt.escapes,
+			}
+			args := []string{"-v", "--", "-x", "--meta", "--meta=1", "file"}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+			if read := readAll(scanner.NewTokenReader(args)); !reflect.DeepEqual(read, tt.expected) {
+				t.Errorf("TokenReader(%q) = %#v, want %#v", args, read, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that, with both "+" and "++" configured in any
+// order, the longest matching prefix wins as with "-" and "--".
+func TestScannerPlusPrefixOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "double plus",
+			args: []string{"++foo"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "++", Name: "foo"},
+			},
+		},
+		{
+			name: "single plus",
+			args: []string{"+foo"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "+", Name: "foo"},
+			},
+		},
+		{
+			name: "triple plus",
+			args: []string{"+++foo"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "++", Name: "+foo"},
+			},
+		},
+		{
+			// As with "--" without a separator, "++" is "+" followed by a name
+			name: "prefixes alone",
+			args: []string{"+", "++"},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "+"},
+				OptionToken{Idx: 1, Prefix: "+", Name: "+"},
+			},
+		},
+		{
+			name: "mixed with dashes",
+			args: []string{"-v", "++foo", "--bar", "+baz"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 1, Prefix: "++", Name: "foo"},
+				OptionToken{Idx: 2, Prefix: "--", Name: "bar"},
+				OptionToken{Idx: 3, Prefix: "+", Name: "baz"},
+			},
+		},
+	}
+
+	orders := [][]string{
+		{"-", "--", "+", "++"},
+		{"++", "+", "--", "-"},
+		{"+", "-", "++", "--"},
+	}
+	for _, prefixes := range orders {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s %q", tt.name, prefixes), func(t *testing.T) {
+				scanner := &Scanner{Prefixes: prefixes}
+				got := scanner.Scan(tt.args)
+				if !reflect.DeepEqual(got, tt.expected) {
+					t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+				}
+			})
+		}
+	}
+}
+
+// This test ensures that [Scanner.PrefixSwitchOptions] changes
+// the prefixes for all the arguments following the listed option.
+func TestScannerPrefixSwitchOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		switches map[string][]string
+		expected []Token
+	}{
+		{
+			name:     "switching to single dash bundling",
+			switches: map[string][]string{"legacy": {"-"}},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "-ab"},
+				OptionToken{Idx: 1, Prefix: "--", Name: "legacy"},
+				OptionToken{Idx: 2, Prefix: "-", Name: "a"},
+				OptionToken{Idx: 2, SubIdx: 1, Prefix: "-", Name: "b"},
+				OptionToken{Idx: 3, Prefix: "-", Name: "-"},
+				OptionToken{Idx: 3, SubIdx: 1, Prefix: "-", Name: "x"},
+				PositionalArgumentToken{Idx: 4, Value: "file"},
+			},
+		},
+		{
+			name: "disabled by default",
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "-ab"},
+				OptionToken{Idx: 1, Prefix: "--", Name: "legacy"},
+				PositionalArgumentToken{Idx: 2, Value: "-ab"},
+				OptionToken{Idx: 3, Prefix: "--", Name: "x"},
+				PositionalArgumentToken{Idx: 4, Value: "file"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:            []string{"--"},
+				BundleShortOptions:  true,
+				PrefixSwitchOptions: tt.switches,
+			}
+			args := []string{"-ab", "--legacy", "-ab", "--x", "file"}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+
+			// The switch persists across batches
+			stream := scanner.NewStreamScanner()
+			batches := append(stream.ScanBatch(args[:2]), stream.ScanBatch(args[2:])...)
+			if !reflect.DeepEqual(batches, tt.expected) {
+				t.Errorf("ScanBatch(%q) = %#v, want %#v", args, batches, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.ResolvePrefix] chooses among
+// the matching prefixes tied according to [Scanner.PrefixLess].
+func TestScannerResolvePrefix(t *testing.T) {
+	byCharacter := func(a, b string) bool {
+		return a[0] < b[0]
+	}
+
+	tests := []struct {
+		name     string
+		arg      string
+		resolve  func(arg string, candidates []string) string
+		expected []Token
+		calls    [][]string
+	}{
+		{
+			name:    "resolver picks the longest",
+			arg:     "--x",
+			resolve: func(arg string, candidates []string) string { return "--" },
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "x"},
+			},
+			calls: [][]string{{"-", "--"}},
+		},
+		{
+			name:    "invalid choice is ignored",
+			arg:     "--x",
+			resolve: func(arg string, candidates []string) string { return "+" },
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "-x"},
+			},
+			calls: [][]string{{"-", "--"}},
+		},
+		{
+			name:    "single candidate",
+			arg:     "+x",
+			resolve: func(arg string, candidates []string) string { return "--" },
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "+", Name: "x"},
+			},
+			calls: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [][]string
+			scanner := &Scanner{
+				Prefixes:   []string{"-", "--", "+"},
+				PrefixLess: byCharacter,
+				ResolvePrefix: func(arg string, candidates []string) string {
+					calls = append(calls, candidates)
+					return tt.resolve(arg, candidates)
+				},
+			}
+			got := scanner.Scan([]string{tt.arg})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.arg, got, tt.expected)
+			}
+			if !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("ResolvePrefix calls = %q, want %q", calls, tt.calls)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.AssignmentChar] emits an
+// [AssignmentToken] for "key=value" positional arguments.
+func TestScannerAssignmentChar(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:       []string{"-", "--"},
+		Separator:      "--",
+		SplitValues:    true,
+		AssignmentChar: "=",
+	}
+	args := []string{"FOO=bar", "novalue", "--opt=x", "=x", "EMPTY=", "--", "BAR=baz"}
+
+	got := scanner.Scan(args)
+
+	expect := []Token{
+		AssignmentToken{Idx: 0, Key: "FOO", Separator: "=", Value: "bar"},
+		PositionalArgumentToken{Idx: 1, Value: "novalue"},
+		OptionToken{Idx: 2, Prefix: "--", Name: "opt", ValueSeparator: "=", Value: "x"},
+		PositionalArgumentToken{Idx: 3, Value: "=x"},
+		AssignmentToken{Idx: 4, Key: "EMPTY", Separator: "="},
+		OptionsArgumentsSeparatorToken{Idx: 5, Separator: "--"},
+		PositionalArgumentToken{Idx: 6, Value: "BAR=baz"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan(%q) = %#v, want %#v", args, got, expect)
+	}
+	if joined := Join(got); !reflect.DeepEqual(joined, args) {
+		t.Errorf("Join() = %q, want %q", joined, args)
+	}
+}
+
+// This test ensures that [Scanner.InlineDisableToken] makes exactly
+// the next argument a positional argument.
+func TestScannerInlineDisableToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "next option is literal",
+			args: []string{"++noopt", "-v", "-x"},
+			expected: []Token{
+				LiteralNextToken{Idx: 0, Marker: "++noopt"},
+				PositionalArgumentToken{Idx: 1, Value: "-v"},
+				OptionToken{Idx: 2, Prefix: "-", Name: "x"},
+			},
+		},
+		{
+			name: "next separator is literal",
+			args: []string{"++noopt", "--", "-x"},
+			expected: []Token{
+				LiteralNextToken{Idx: 0, Marker: "++noopt"},
+				PositionalArgumentToken{Idx: 1, Value: "--"},
+				OptionToken{Idx: 2, Prefix: "-", Name: "x"},
+			},
+		},
+		{
+			name: "marker at the end",
+			args: []string{"-x", "++noopt"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "x"},
+				LiteralNextToken{Idx: 1, Marker: "++noopt"},
+			},
+		},
+		{
+			name: "marker after the separator",
+			args: []string{"--", "++noopt", "-v"},
+			expected: []Token{
+				OptionsArgumentsSeparatorToken{Idx: 0, Separator: "--"},
+				PositionalArgumentToken{Idx: 1, Value: "++noopt"},
+				PositionalArgumentToken{Idx: 2, Value: "-v"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:           []string{"-"},
+				Separator:          "--",
+				InlineDisableToken: "++noopt",
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+			if joined := Join(got); !reflect.DeepEqual(joined, tt.args) {
+				t.Errorf("Join() = %q, want %q", joined, tt.args)
+			}
+		})
+	}
+}
+
+// This test ensures that the options promoted by [Scanner.AfterSeparatorEscapes]
+// and [Scanner.SeparatorOnlyAfterOptions] get the same processing of the others.
+func TestScannerPromotedOptionsProcessing(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:                  []string{"-", "--"},
+		Separator:                 "--",
+		SeparatorOnlyAfterOptions: true,
+		AfterSeparatorEscapes:     []string{"--Meta"},
+		RecordForm:                true,
+		RecordPrefixConfigIndex:   true,
+		Canonicalize:              strings.ToLower,
+		FoldName:                  strings.ToUpper,
+	}
+	args := []string{"--", "-v", "--", "--Meta"}
+
+	got := scanner.Scan(args)
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "--", Form: LongForm, PrefixConfigIndex: 1},
+		OptionToken{Idx: 1, Prefix: "-", Form: ShortForm, Name: "v", CanonicalName: "v", FoldedName: "V"},
+		OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+		OptionToken{Idx: 3, Prefix: "--", Form: LongForm, PrefixConfigIndex: 1, Name: "Meta", CanonicalName: "meta", FoldedName: "META"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan(%q) = %#v, want %#v", args, got, expect)
+	}
+}
+
+// This test ensures that [Scanner.SeparatorOnlyAfterOptions] makes
+// a leading separator an empty option.
+func TestScannerSeparatorOnlyAfterOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		args     []string
+		expected []Token
+	}{
+		{
+			name:    "leading separator when disabled",
+			enabled: false,
+			args:    []string{"--", "a"},
+			expected: []Token{
+				OptionsArgumentsSeparatorToken{Idx: 0, Separator: "--"},
+				PositionalArgumentToken{Idx: 1, Value: "a"},
+			},
+		},
+		{
+			name:    "leading separator when enabled",
+			enabled: true,
+			args:    []string{"--", "a"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--"},
+				PositionalArgumentToken{Idx: 1, Value: "a"},
+			},
+		},
+		{
+			name:    "separator after an option when enabled",
+			enabled: true,
+			args:    []string{"-v", "--", "a"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				PositionalArgumentToken{Idx: 2, Value: "a"},
+			},
+		},
+		{
+			name:    "separator after a positional when enabled",
+			enabled: true,
+			args:    []string{"a", "--", "-v"},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "a"},
+				OptionToken{Idx: 1, Prefix: "--"},
+				OptionToken{Idx: 2, Prefix: "-", Name: "v"},
+			},
+		},
+		{
+			name:    "second separator when enabled",
+			enabled: true,
+			args:    []string{"--", "--", "-v"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--"},
+				OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+				PositionalArgumentToken{Idx: 2, Value: "-v"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:                  []string{"-", "--"},
+				Separator:                 "--",
+				SeparatorOnlyAfterOptions: tt.enabled,
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.FirstArgIsVerb] only emits
+// the first argument as a [SubcommandToken].
+func TestScannerFirstArgIsVerb(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "verb followed by options",
+			args: []string{"status", "-v", "commit"},
+			expected: []Token{
+				SubcommandToken{Idx: 0, Name: "status"},
+				OptionToken{Idx: 1, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 2, Value: "commit"},
+			},
+		},
+		{
+			name: "verb starting with a prefix",
+			args: []string{"-v", "-x"},
+			expected: []Token{
+				SubcommandToken{Idx: 0, Name: "-v"},
+				OptionToken{Idx: 1, Prefix: "-", Name: "x"},
+			},
+		},
+		{
+			name:     "empty arguments",
+			args:     []string{},
+			expected: []Token{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:       []string{"-"},
+				Separator:      "--",
+				FirstArgIsVerb: true,
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.SplitValues] splits short options
+// at the value separator, including within bundles.
+func TestScannerShortOptionValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		bundleOnly bool
+		expected   []Token
+	}{
+		{
+			name: "short option with value",
+			args: []string{"-f=x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "f", ValueSeparator: "=", Value: "x"},
+			},
+		},
+		{
+			name: "short option without value",
+			args: []string{"-f"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "f"},
+			},
+		},
+		{
+			name:       "bundle ending with a value",
+			args:       []string{"-af=x"},
+			bundleOnly: true,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "a"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "f", ValueSeparator: "=", Value: "x"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, bundle := range []bool{false, true} {
+			if tt.bundleOnly && !bundle {
+				continue
+			}
+			t.Run(fmt.Sprintf("%s with bundling %v", tt.name, bundle), func(t *testing.T) {
+				scanner := &Scanner{
+					Prefixes:           []string{"-", "--"},
+					Separator:          "--",
+					SplitValues:        true,
+					BundleShortOptions: bundle,
+				}
+				got := scanner.Scan(tt.args)
+				if !reflect.DeepEqual(got, tt.expected) {
+					t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+				}
+				if joined := Join(got); !reflect.DeepEqual(joined, tt.args) {
+					t.Errorf("Join() = %q, want %q", joined, tt.args)
+				}
+			})
+		}
+	}
+}
+
+// This test ensures that the [OptionToken] Consumed field records the
+// consumed values and that [Join] reconstructs the original arguments.
+func TestScannerConsumed(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "single value",
+			args: []string{"--file", "x", "y"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", Value: "x", Consumed: 1},
+				PositionalArgumentToken{Idx: 2, Value: "y"},
+			},
+		},
+		{
+			name: "arity three",
+			args: []string{"--point", "1", "2", "3", "y"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "point", Consumed: 3, ValueList: []string{"1", "2", "3"}},
+				PositionalArgumentToken{Idx: 4, Value: "y"},
+			},
+		},
+		{
+			name: "flag",
+			args: []string{"-v", "y"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "y"},
+			},
+		},
+		{
+			name: "attached value",
+			args: []string{"--file=x", "y"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", ValueSeparator: "=", Value: "x"},
+				PositionalArgumentToken{Idx: 1, Value: "y"},
+			},
+		},
+		{
+			name: "pending value",
+			args: []string{"--file=", "x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", ValueSeparator: "=", Value: "x", Consumed: 1},
+			},
+		},
+		{
+			name: "bundle",
+			args: []string{"-vo", "out"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "o", Value: "out", Consumed: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:                     []string{"-", "--"},
+				Separator:                    "--",
+				SplitValues:                  true,
+				TreatTrailingEqualsAsPending: true,
+				BundleShortOptions:           true,
+				OptionsWithArity:             map[string]int{"file": 1, "point": 3, "o": 1},
+			}
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+			if joined := Join(got); !reflect.DeepEqual(joined, tt.args) {
+				t.Errorf("Join() = %q, want %q", joined, tt.args)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.TreatExtraSeparatorDashesAsSeparator]
+// makes longer runs of dashes act as the separator.
+func TestScannerTreatExtraSeparatorDashesAsSeparator(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		arg      string
+		expected []Token
+	}{
+		{
+			name:    "three dashes by default",
+			enabled: false,
+			arg:     "---",
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "-"},
+				OptionToken{Idx: 1, Prefix: "-", Name: "v"},
+			},
+		},
+		{
+			name:    "four dashes by default",
+			enabled: false,
+			arg:     "----",
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "--"},
+				OptionToken{Idx: 1, Prefix: "-", Name: "v"},
+			},
+		},
+		{
+			name:    "three dashes when enabled",
+			enabled: true,
+			arg:     "---",
+			expected: []Token{
+				OptionsArgumentsSeparatorToken{Idx: 0, Separator: "---"},
+				PositionalArgumentToken{Idx: 1, Value: "-v"},
+			},
+		},
+		{
+			name:    "four dashes when enabled",
+			enabled: true,
+			arg:     "----",
+			expected: []Token{
+				OptionsArgumentsSeparatorToken{Idx: 0, Separator: "----"},
+				PositionalArgumentToken{Idx: 1, Value: "-v"},
+			},
+		},
+		{
+			name:    "dashes followed by a name when enabled",
+			enabled: true,
+			arg:     "---x",
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "-x"},
+				OptionToken{Idx: 1, Prefix: "-", Name: "v"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:                             []string{"-", "--"},
+				Separator:                            "--",
+				TreatExtraSeparatorDashesAsSeparator: tt.enabled,
+			}
+			args := []string{tt.arg, "-v"}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.RecordForm] classifies options
+// according to the length of their prefix.
+func TestScannerRecordForm(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected []OptionForm
+	}{
+		{
+			name:     "enabled",
+			enabled:  true,
+			expected: []OptionForm{ShortForm, LongForm, ShortForm},
+		},
+		{
+			name:     "disabled by default",
+			enabled:  false,
+			expected: []OptionForm{UnknownForm, UnknownForm, UnknownForm},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:   []string{"-", "--", "+"},
+				RecordForm: tt.enabled,
+			}
+			got := Collect(scanner.Scan([]string{"-v", "--verbose", "+trace"}), func(token Token) (OptionForm, bool) {
+				tk, ok := token.(OptionToken)
+				return tk.Form, ok
+			})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Form = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [OptionForm.String] names each form.
+func TestOptionFormString(t *testing.T) {
+	for form, expected := range map[OptionForm]string{
+		UnknownForm: "unknown",
+		ShortForm:   "short",
+		LongForm:    "long",
+	} {
+		if got := form.String(); got != expected {
+			t.Errorf("OptionForm(%d).String() = %q, want %q", form, got, expected)
+		}
+	}
+}
+
+// This test ensures that [Scanner.TransformPositional] transforms the
+// positional values while preserving the original RawValue.
+func TestScannerTransformPositional(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:            []string{"-"},
+		Separator:           "--",
+		TransformPositional: strings.ToUpper,
+	}
+	args := []string{"a.txt", "-v", "--", "b.txt"}
+
+	got := scanner.Scan(args)
+
+	expect := []Token{
+		PositionalArgumentToken{Idx: 0, Value: "A.TXT", RawValue: "a.txt"},
+		OptionToken{Idx: 1, Prefix: "-", Name: "v"},
+		OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+		PositionalArgumentToken{Idx: 3, Value: "B.TXT", RawValue: "b.txt"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan(%q) = %#v, want %#v", args, got, expect)
+	}
+	if joined := Join(got); !reflect.DeepEqual(joined, args) {
+		t.Errorf("Join() = %q, want %q", joined, args)
+	}
+}
+
+// This test ensures that [Scanner.PathHeuristic] makes arguments
+// looking like UNIX paths positional when "/" is a prefix.
+func TestScannerPathHeuristic(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected []Token
+	}{
+		{
+			name:    "enabled",
+			enabled: true,
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "/etc/passwd"},
+				OptionToken{Idx: 1, Prefix: "/", Name: "verbose"},
+				OptionToken{Idx: 2, Prefix: "/", Name: "out", ValueSeparator: ":", Value: "file"},
+				OptionToken{Idx: 3, Prefix: "/", Name: "out", ValueSeparator: ":", Value: "dir/file"},
+				PositionalArgumentToken{Idx: 4, Value: "//server/share"},
+			},
+		},
+		{
+			name:    "disabled by default",
+			enabled: false,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "/", Name: "etc/passwd"},
+				OptionToken{Idx: 1, Prefix: "/", Name: "verbose"},
+				OptionToken{Idx: 2, Prefix: "/", Name: "out", ValueSeparator: ":", Value: "file"},
+				OptionToken{Idx: 3, Prefix: "/", Name: "out", ValueSeparator: ":", Value: "dir/file"},
+				OptionToken{Idx: 4, Prefix: "/", Name: "/server/share"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewWindowsScanner()
+			scanner.PathHeuristic = tt.enabled
+			args := []string{"/etc/passwd", "/verbose", "/out:file", "/out:dir/file", "//server/share"}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.StripLeadingInvisibles] removes
+// the leading format characters before classifying arguments.
+func TestScannerStripLeadingInvisibles(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected []Token
+	}{
+		{
+			name:    "enabled",
+			enabled: true,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "flag", RawName: "\uFEFF--flag"},
+				OptionToken{Idx: 1, Prefix: "--", Name: "verbose"},
+				PositionalArgumentToken{Idx: 2, Value: "file.txt", RawValue: "\u200Bfile.txt"},
+				OptionsArgumentsSeparatorToken{Idx: 3, Separator: "--"},
+				PositionalArgumentToken{Idx: 4, Value: "\uFEFF-x"},
+			},
+		},
+		{
+			name:    "disabled by default",
+			enabled: false,
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "\uFEFF--flag"},
+				OptionToken{Idx: 1, Prefix: "--", Name: "verbose"},
+				PositionalArgumentToken{Idx: 2, Value: "\u200Bfile.txt"},
+				OptionsArgumentsSeparatorToken{Idx: 3, Separator: "--"},
+				PositionalArgumentToken{Idx: 4, Value: "\uFEFF-x"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:               []string{"-", "--"},
+				Separator:              "--",
+				StripLeadingInvisibles: tt.enabled,
+			}
+			args := []string{"\uFEFF--flag", "--verbose", "\u200Bfile.txt", "--", "\uFEFF-x"}
+			got := scanner.Scan(args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// hugeTailArgs returns arguments consisting of an option, the
+// separator, and a tail containing the given number of arguments.
+func hugeTailArgs(size int) []string {
+	args := []string{"-v", "--"}
+	for idx := range size {
+		args = append(args, "-arg"+strconv.Itoa(idx))
+	}
+	return args
+}
+
+// This test ensures that the separator fast path assigns the
+// correct indices to a large tail of positional arguments.
+func TestScannerSeparatorHugeTailIndices(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-"}, Separator: "--"}
+	args := hugeTailArgs(1000)
+
+	tokens := scanner.Scan(args)
+
+	if len(tokens) != len(args) {
+		t.Fatalf("Expected %d tokens, got %d", len(args), len(tokens))
+	}
+	for idx := 2; idx < len(tokens); idx++ {
+		expect := PositionalArgumentToken{Idx: idx, Value: args[idx]}
+		if tokens[idx] != expect {
+			t.Fatalf("Expected %#v, got %#v", expect, tokens[idx])
+		}
+	}
+}
+
+// BenchmarkScanSeparatorHugeTail measures the separator fast path
+// when the separator is followed by 100k arguments.
+func BenchmarkScanSeparatorHugeTail(b *testing.B) {
+	scanner := &Scanner{Prefixes: []string{"-", "--"}, Separator: "--"}
+	args := hugeTailArgs(100_000)
+	b.ReportAllocs()
+	for b.Loop() {
+		scanner.Scan(args)
+	}
+}
+
+// This test ensures that [Scanner.XStylePrefixes] keeps the whole
+// remainder as the option name while splitting other options.
+func TestScannerXStylePrefixes(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:           []string{"-", "--"},
+		SplitValues:        true,
+		BundleShortOptions: true,
+		XStylePrefixes:     map[string]bool{"-X": true},
+	}
+
+	got := scanner.Scan([]string{"-Xmx512m", "-Xlog:gc=debug", "--other=x", "-ab"})
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "-", Name: "Xmx512m"},
+		OptionToken{Idx: 1, Prefix: "-", Name: "Xlog:gc=debug"},
+		OptionToken{Idx: 2, Prefix: "--", Name: "other", ValueSeparator: "=", Value: "x"},
+		OptionToken{Idx: 3, SubIdx: 0, Prefix: "-", Name: "a"},
+		OptionToken{Idx: 3, SubIdx: 1, Prefix: "-", Name: "b"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Scan() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [Scanner.PrefixLess] replaces the default
+// prefix order and therefore changes which prefix matches.
+func TestScannerPrefixLess(t *testing.T) {
+	preferPlus := func(a, b string) bool {
+		if (a == "+") != (b == "+") {
+			return a == "+"
+		}
+		return a < b
+	}
+
+	tests := []struct {
+		name     string
+		less     func(a, b string) bool
+		expected []Token
+	}{
+		{
+			name: "default order",
+			less: nil,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "++", Name: "x"},
+			},
+		},
+		{
+			name: "custom order",
+			less: preferPlus,
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "+", Name: "+x"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:   []string{"+", "++"},
+				PrefixLess: tt.less,
+			}
+			got := scanner.Scan([]string{"++x"})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that arguments consisting of a prefix only
+// are positional arguments unless they are the separator.
+func TestScannerPrefixOnlyArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		scanner  *Scanner
+		arg      string
+		expected Token
+	}{
+		{
+			name:     "single dash without separator",
+			scanner:  &Scanner{Prefixes: []string{"-", "--"}},
+			arg:      "-",
+			expected: PositionalArgumentToken{Idx: 0, Value: "-"},
+		},
+		{
+			name:     "double dash without separator is a dash option",
+			scanner:  &Scanner{Prefixes: []string{"-", "--"}},
+			arg:      "--",
+			expected: OptionToken{Idx: 0, Prefix: "-", Name: "-"},
+		},
+		{
+			name:     "double dash with double dash prefix only",
+			scanner:  &Scanner{Prefixes: []string{"--"}},
+			arg:      "--",
+			expected: PositionalArgumentToken{Idx: 0, Value: "--"},
+		},
+		{
+			name:     "double dash with separator",
+			scanner:  &Scanner{Prefixes: []string{"-", "--"}, Separator: "--"},
+			arg:      "--",
+			expected: OptionsArgumentsSeparatorToken{Idx: 0, Separator: "--"},
+		},
+		{
+			name:     "plus",
+			scanner:  &Scanner{Prefixes: []string{"-", "+"}, Separator: "--"},
+			arg:      "+",
+			expected: PositionalArgumentToken{Idx: 0, Value: "+"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.scanner.Scan([]string{tt.arg})
+			if len(got) != 1 || !reflect.DeepEqual(got[0], tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.arg, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// sections.go - Scanning independent sections of the command line.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import "reflect"
+
+// ScanSections splits args at each occurrence of the [Scanner.SectionDelimiter]
+// and scans each section independently, returning the tokens of each section.
+//
+// We split before scanning, therefore the delimiter separates sections even
+// after the separator. The delimiter does not produce any token. Token indexes
+// refer to the original args rather than to the section, so that diagnostics
+// can point to the original argument.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanSections(args []string) [][]Token {
+	var sections [][]Token
+	start := 0
+	for idx := 0; idx <= len(args); idx++ {
+		if idx < len(args) && (sx.SectionDelimiter == "" || args[idx] != sx.SectionDelimiter) {
+			continue
+		}
+		tokens := sx.Scan(args[start:idx])
+		for tidx, token := range tokens {
+			tokens[tidx] = shiftIndex(token, start)
+		}
+		sections = append(sections, tokens)
+		start = idx + 1
+	}
+	return sections
+}
+
+// shiftIndex returns a copy of the token with offset added to its Idx field.
+func shiftIndex(token Token, offset int) Token {
+	value := reflect.ValueOf(token)
+	if offset == 0 || value.Kind() != reflect.Struct {
+		return token
+	}
+	clone := reflect.New(value.Type()).Elem()
+	clone.Set(value)
+	if field := clone.FieldByName("Idx"); field.IsValid() && field.CanSet() && field.Kind() == reflect.Int {
+		field.SetInt(field.Int() + int64(offset))
+	}
+	return clone.Interface().(Token)
+}
@@ -0,0 +1,52 @@
+// sections_test.go - Tests for scanning independent sections.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [*Scanner.ScanSections] scans each
+// section independently using the scanner configuration.
+func TestScanSections(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:         []string{"-", "--"},
+		Separator:        "--",
+		SectionDelimiter: "+++",
+	}
+
+	got := scanner.ScanSections([]string{"toolA", "-v", "--", "-x", "+++", "toolB", "--file", "y"})
+
+	expect := [][]Token{
+		{
+			PositionalArgumentToken{Idx: 0, Value: "toolA"},
+			OptionToken{Idx: 1, Prefix: "-", Name: "v"},
+			OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+			PositionalArgumentToken{Idx: 3, Value: "-x"},
+		},
+		{
+			PositionalArgumentToken{Idx: 5, Value: "toolB"},
+			OptionToken{Idx: 6, Prefix: "--", Name: "file"},
+			PositionalArgumentToken{Idx: 7, Value: "y"},
+		},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanSections() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [*Scanner.ScanSections] returns a
+// single section when there is no delimiter.
+func TestScanSectionsWithoutDelimiter(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-"}}
+	args := []string{"-v", "+++", "x"}
+
+	got := scanner.ScanSections(args)
+
+	expect := [][]Token{scanner.Scan(args)}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanSections() = %#v, want %#v", got, expect)
+	}
+}
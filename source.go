@@ -0,0 +1,132 @@
+// source.go - Scanning arguments originating from multiple sources and files.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ScanSources scans the concatenation of the sources (e.g., the arguments from
+// a configuration file, the environment, and the command line) and sets the
+// [Origin] Source field of each token to the label of the source containing it.
+//
+// The labels are matched to the sources by position and missing labels are
+// empty. Token indexes refer to the concatenation of the sources. A token
+// spanning multiple arguments uses the source of its first argument.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanSources(sources [][]string, labels []string) []Token {
+	var (
+		args   []string
+		origin []string
+	)
+	for idx, source := range sources {
+		var label string
+		if idx < len(labels) {
+			label = labels[idx]
+		}
+		for _, arg := range source {
+			args = append(args, arg)
+			origin = append(origin, label)
+		}
+	}
+
+	tokens := sx.Scan(args)
+	for idx, token := range tokens {
+		if pos := token.Index(); pos < len(origin) {
+			tokens[idx] = withOrigin(token, Origin{Source: origin[pos]})
+		}
+	}
+	return tokens
+}
+
+// ScanFile reads the named file using readFile (e.g., [os.ReadFile]), splits
+// its content into arguments, and scans them. Each token has the [Origin]
+// Source field set to the file name and the SourceLine and SourceCol fields
+// set to the 1-based line and column (in characters) of its first argument,
+// which allows precise diagnostics (e.g., "error in config at line 12").
+//
+// We split the content using the same rules of [SplitArgs], except that an
+// unquoted "#" at the beginning of an argument starts a comment extending to
+// the end of the line.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanFile(name string, readFile func(name string) ([]byte, error)) ([]Token, error) {
+	data, err := readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	content := string(data)
+	words, err := splitWords(content, true)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	args := make([]string, 0, len(words))
+	for _, word := range words {
+		args = append(args, word.value)
+	}
+
+	tokens := sx.Scan(args)
+	for idx, token := range tokens {
+		if pos := token.Index(); pos < len(words) {
+			line, col := lineAndColumn(content, words[pos].offset)
+			tokens[idx] = withOrigin(token, Origin{Source: name, SourceLine: line, SourceCol: col})
+		}
+	}
+	return tokens, nil
+}
+
+// lineAndColumn returns the 1-based line and column of the offset within content.
+func lineAndColumn(content string, offset int) (int, int) {
+	before := content[:offset]
+	line := strings.Count(before, "\n") + 1
+	start := strings.LastIndexByte(before, '\n') + 1
+	return line, utf8.RuneCountInString(before[start:]) + 1
+}
+
+// Origin describes where the argument producing a token comes from when
+// using [*Scanner.ScanSources] or [*Scanner.ScanFile]. All the tokens
+// embed an Origin, except the [EndToken], which has no argument.
+type Origin struct {
+	// Source identifies the source of the argument.
+	Source string
+
+	// SourceLine is the 1-based line of the argument when using [*Scanner.ScanFile].
+	SourceLine int
+
+	// SourceCol is the 1-based column of the argument when using [*Scanner.ScanFile].
+	SourceCol int
+}
+
+// withOrigin returns a copy of the token with the given [Origin].
+func withOrigin(token Token, origin Origin) Token {
+	switch tk := token.(type) {
+	case OptionToken:
+		tk.Origin = origin
+		return tk
+	case PositionalArgumentToken:
+		tk.Origin = origin
+		return tk
+	case OptionsArgumentsSeparatorToken:
+		tk.Origin = origin
+		return tk
+	case RawRemainderToken:
+		tk.Origin = origin
+		return tk
+	case SubcommandToken:
+		tk.Origin = origin
+		return tk
+	case AssignmentToken:
+		tk.Origin = origin
+		return tk
+	case LiteralNextToken:
+		tk.Origin = origin
+		return tk
+	default:
+		return token
+	}
+}
@@ -0,0 +1,121 @@
+// source_test.go - Tests for scanning arguments from multiple sources.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [*Scanner.ScanSources] tags each
+// token with the label of the source containing it.
+func TestScanSources(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:         []string{"-", "--"},
+		Separator:        "--",
+		OptionsWithArity: map[string]int{"x": 1},
+	}
+
+	got := scanner.ScanSources(
+		[][]string{{"--x", "1", "-v"}, {"--x", "2", "file.txt"}},
+		[]string{"config", "cmdline"},
+	)
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "--", Name: "x", Value: "1", Consumed: 1, Origin: Origin{Source: "config"}},
+		OptionToken{Idx: 2, Prefix: "-", Name: "v", Origin: Origin{Source: "config"}},
+		OptionToken{Idx: 3, Prefix: "--", Name: "x", Value: "2", Consumed: 1, Origin: Origin{Source: "cmdline"}},
+		PositionalArgumentToken{Idx: 5, Value: "file.txt", Origin: Origin{Source: "cmdline"}},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanSources() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [*Scanner.ScanSources] handles the
+// separator, the remainder, and missing labels.
+func TestScanSourcesSeparatorAndMissingLabels(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:              []string{"-"},
+		Separator:             "--",
+		CaptureRemainderAsRaw: true,
+	}
+
+	got := scanner.ScanSources([][]string{{"--"}, {"a", "b"}}, []string{"env"})
+
+	expect := []Token{
+		OptionsArgumentsSeparatorToken{Idx: 0, Separator: "--", Origin: Origin{Source: "env"}},
+		RawRemainderToken{Idx: 1, Args: []string{"a", "b"}, Origin: Origin{Source: ""}},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanSources() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [*Scanner.ScanSources] works with an [EndToken].
+func TestScanSourcesWithEndToken(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-"}, EmitEndToken: true}
+	got := scanner.ScanSources([][]string{{"-v"}}, []string{"cmdline"})
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "-", Name: "v", Origin: Origin{Source: "cmdline"}},
+		EndToken{Idx: 1},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanSources() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [*Scanner.ScanFile] records the file name
+// and the line and column of each token, skipping comments.
+func TestScanFile(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:         []string{"-", "--"},
+		Separator:        "--",
+		OptionsWithArity: map[string]int{"x": 1},
+	}
+	content := "# comment -v\n--x 1\n  -v 'a b' # trailing\nça file.txt\n"
+	readFile := func(name string) ([]byte, error) {
+		if name != "config" {
+			t.Fatalf("readFile(%q), want %q", name, "config")
+		}
+		return []byte(content), nil
+	}
+
+	got, err := scanner.ScanFile("config", readFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "--", Name: "x", Value: "1", Consumed: 1, Origin: Origin{Source: "config", SourceLine: 2, SourceCol: 1}},
+		OptionToken{Idx: 2, Prefix: "-", Name: "v", Origin: Origin{Source: "config", SourceLine: 3, SourceCol: 3}},
+		PositionalArgumentToken{Idx: 3, Value: "a b", Origin: Origin{Source: "config", SourceLine: 3, SourceCol: 6}},
+		PositionalArgumentToken{Idx: 4, Value: "ça", Origin: Origin{Source: "config", SourceLine: 4, SourceCol: 1}},
+		PositionalArgumentToken{Idx: 5, Value: "file.txt", Origin: Origin{Source: "config", SourceLine: 4, SourceCol: 4}},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanFile() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [*Scanner.ScanFile] returns read and split errors.
+func TestScanFileErrors(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-"}}
+
+	errRead := errors.New("mocked error")
+	_, err := scanner.ScanFile("config", func(string) ([]byte, error) {
+		return nil, errRead
+	})
+	if !errors.Is(err, errRead) {
+		t.Errorf("ScanFile() error = %v, want %v", err, errRead)
+	}
+
+	_, err = scanner.ScanFile("config", func(string) ([]byte, error) {
+		return []byte("-v\n'unterminated"), nil
+	})
+	if !errors.Is(err, ErrUnterminatedQuote) {
+		t.Errorf("ScanFile() error = %v, want %v", err, ErrUnterminatedQuote)
+	}
+}
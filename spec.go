@@ -0,0 +1,71 @@
+// spec.go - Parsing compact option specifications.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ErrInvalidOptionSpec indicates that an option specification is malformed.
+var ErrInvalidOptionSpec = errors.New("invalid option spec")
+
+// ParseOptionSpec parses a getopt-like specification into a map suitable
+// for [Scanner.OptionsWithArity].
+//
+// The specification is a sequence of entries, each consisting of a
+// single-character option name followed by an optional arity:
+//
+//  1. no suffix or "+" means that the option is a flag (arity zero)
+//
+//  2. ":" means that the option takes one value (arity one)
+//
+//  3. ":" followed by decimal digits means that the option takes that
+//     many values (e.g., "p:3" means that "p" takes three values)
+//
+// Option names cannot be ":", "+", or ASCII digits. For example, "f:v+p:3"
+// means that "f" takes one value, "v" is a flag, and "p" takes three values.
+func ParseOptionSpec(spec string) (map[string]int, error) {
+	options := make(map[string]int)
+	for pos := 0; pos < len(spec); {
+		name, size := utf8.DecodeRuneInString(spec[pos:])
+		if name == utf8.RuneError || name == ':' || name == '+' || (name >= '0' && name <= '9') {
+			return nil, fmt.Errorf("%w: expected option name at offset %d", ErrInvalidOptionSpec, pos)
+		}
+		if _, found := options[string(name)]; found {
+			return nil, fmt.Errorf("%w: duplicate option %q at offset %d", ErrInvalidOptionSpec, name, pos)
+		}
+		pos += size
+
+		arity := 0
+		switch {
+		case pos < len(spec) && spec[pos] == '+':
+			pos++
+
+		case pos < len(spec) && spec[pos] == ':':
+			pos++
+			start := pos
+			for pos < len(spec) && isDigit(spec[pos]) {
+				pos++
+			}
+			arity = 1
+			if pos > start {
+				value, err := strconv.Atoi(spec[start:pos])
+				if err != nil {
+					return nil, fmt.Errorf("%w: invalid arity at offset %d", ErrInvalidOptionSpec, start)
+				}
+				arity = value
+			}
+		}
+		options[string(name)] = arity
+	}
+	return options, nil
+}
+
+// isDigit returns whether ch is an ASCII digit.
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
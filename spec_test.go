@@ -0,0 +1,112 @@
+// spec_test.go - Tests for parsing compact option specifications.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [ParseOptionSpec] parses valid specs
+// and rejects malformed ones.
+func TestParseOptionSpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected map[string]int
+		err      error
+	}{
+		{
+			name:     "empty",
+			spec:     "",
+			expected: map[string]int{},
+		},
+		{
+			name:     "mixed arities",
+			spec:     "f:v+p:3",
+			expected: map[string]int{"f": 1, "v": 0, "p": 3},
+		},
+		{
+			name:     "bare flags and multi-digit arity",
+			spec:     "abc:12",
+			expected: map[string]int{"a": 0, "b": 0, "c": 12},
+		},
+		{
+			name: "missing name",
+			spec: ":f",
+			err:  ErrInvalidOptionSpec,
+		},
+		{
+			name: "digit name",
+			spec: "f:v+3",
+			err:  ErrInvalidOptionSpec,
+		},
+		{
+			name: "duplicate option",
+			spec: "f:f",
+			err:  ErrInvalidOptionSpec,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOptionSpec(tt.spec)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseOptionSpec(%q) error = %v, want %v", tt.spec, err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseOptionSpec(%q) = %v, want %v", tt.spec, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that a parsed spec configures the [Scanner.OptionsWithArity].
+func TestParseOptionSpecWithScanner(t *testing.T) {
+	arity, err := ParseOptionSpec("f:v+p:3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := &Scanner{
+		Prefixes:         []string{"-"},
+		Separator:        "--",
+		OptionsWithArity: arity,
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+	}{
+		{
+			name: "values are consumed",
+			args: []string{"-f", "file", "-v", "-p", "1", "2", "3", "x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "f", Value: "file", Consumed: 1},
+				OptionToken{Idx: 2, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 3, Prefix: "-", Name: "p", Consumed: 3, ValueList: []string{"1", "2", "3"}},
+				PositionalArgumentToken{Idx: 7, Value: "x"},
+			},
+		},
+		{
+			name: "not enough values",
+			args: []string{"-p", "1", "2"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "p", MissingValue: true},
+				PositionalArgumentToken{Idx: 1, Value: "1"},
+				PositionalArgumentToken{Idx: 2, Value: "2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scanner.Scan(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// split.go - Splitting command lines into arguments.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnterminatedQuote indicates that a command line contains a quote without its closing quote.
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// ErrTrailingBackslash indicates that a command line ends with an unescaped backslash.
+var ErrTrailingBackslash = errors.New("trailing backslash")
+
+// SplitArgs splits a command line into arguments using a subset of the
+// POSIX shell quoting rules:
+//
+//  1. unquoted whitespace separates arguments
+//
+//  2. characters between single quotes are literal
+//
+//  3. within double quotes, a backslash only escapes "\", "$", "`", and "\""
+//
+//  4. outside quotes, a backslash escapes the following character
+//
+// We do not perform any expansion (e.g., variables, globs).
+func SplitArgs(line string) ([]string, error) {
+	words, err := splitWords(line, false)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, len(words))
+	for _, word := range words {
+		args = append(args, word.value)
+	}
+	return args, nil
+}
+
+// splitWord is an argument produced by [splitWords].
+type splitWord struct {
+	// value is the argument value.
+	value string
+
+	// quoted indicates that the argument contained quoted characters.
+	quoted bool
+
+	// offset is the byte offset where the argument starts.
+	offset int
+}
+
+// splitWords implements [SplitArgs] and also returns whether each argument
+// contained quoted characters (i.e., single or double quotes) and the offset
+// where it starts. When comments is true, an unquoted "#" at the beginning
+// of an argument starts a comment extending to the end of the line.
+func splitWords(line string, comments bool) ([]splitWord, error) {
+	var (
+		words   []splitWord
+		current strings.Builder
+		inWord  bool
+		isQuote bool
+		offset  int
+	)
+
+	flush := func() {
+		if inWord {
+			words = append(words, splitWord{value: current.String(), quoted: isQuote, offset: offset})
+		}
+		current.Reset()
+		inWord, isQuote = false, false
+	}
+
+	for idx := 0; idx < len(line); idx++ {
+		ch := line[idx]
+		if !inWord {
+			offset = idx
+		}
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			flush()
+
+		case ch == '#' && comments && !inWord:
+			for idx+1 < len(line) && line[idx+1] != '\n' {
+				idx++
+			}
+
+		case ch == '\\':
+			if idx+1 >= len(line) {
+				return nil, ErrTrailingBackslash
+			}
+			idx++
+			current.WriteByte(line[idx])
+			inWord = true
+
+		case ch == '\'':
+			end := strings.IndexByte(line[idx+1:], '\'')
+			if end < 0 {
+				return nil, ErrUnterminatedQuote
+			}
+			current.WriteString(line[idx+1 : idx+1+end])
+			idx += end + 1
+			inWord, isQuote = true, true
+
+		case ch == '"':
+			inWord, isQuote = true, true
+			for idx++; ; idx++ {
+				if idx >= len(line) {
+					return nil, ErrUnterminatedQuote
+				}
+				if line[idx] == '"' {
+					break
+				}
+				if line[idx] == '\\' && idx+1 < len(line) && strings.IndexByte("\\$`\"", line[idx+1]) >= 0 {
+					idx++
+				}
+				current.WriteByte(line[idx])
+			}
+
+		default:
+			current.WriteByte(ch)
+			inWord = true
+		}
+	}
+
+	flush()
+	return words, nil
+}
+
+// ScanLineWithQuotes splits the line using [SplitArgs] and scans the resulting
+// arguments, setting the Quoted field of each [PositionalArgumentToken] whose
+// argument originally contained quoted characters.
+//
+// The line MUST NOT include the program name as the first argument.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanLineWithQuotes(line string) ([]Token, error) {
+	words, err := splitWords(line, false)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, len(words))
+	for _, word := range words {
+		args = append(args, word.value)
+	}
+	tokens := sx.Scan(args)
+	for idx, token := range tokens {
+		if tk, ok := token.(PositionalArgumentToken); ok && tk.Idx < len(words) && words[tk.Idx].quoted {
+			tk.Quoted = true
+			tokens[idx] = tk
+		}
+	}
+	return tokens, nil
+}
+
+// JoinContinuations merges each argument ending with an unescaped backslash
+// with the following argument, removing the backslash (e.g., "--msg=a\" and
+// "b" become "--msg=ab"). A backslash preceded by another backslash is escaped
+// and does not cause merging. A trailing backslash in the last argument is
+// left unchanged. Use this function to preprocess the arguments before scanning.
+func JoinContinuations(args []string) []string {
+	var output []string
+	var pending strings.Builder
+	continuing := false
+	for idx, arg := range args {
+		if isContinuation(arg) && idx+1 < len(args) {
+			pending.WriteString(arg[:len(arg)-1])
+			continuing = true
+			continue
+		}
+		if continuing {
+			pending.WriteString(arg)
+			arg = pending.String()
+			pending.Reset()
+			continuing = false
+		}
+		output = append(output, arg)
+	}
+	return output
+}
+
+// isContinuation returns whether arg ends with an unescaped backslash.
+func isContinuation(arg string) bool {
+	count := len(arg) - len(strings.TrimRight(arg, `\`))
+	return count%2 == 1
+}
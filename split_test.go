@@ -0,0 +1,135 @@
+// split_test.go - Tests for splitting command lines into arguments.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [SplitArgs] honours the quoting rules.
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+		err      error
+	}{
+		{
+			name:     "plain words",
+			line:     "  -v  file.txt\tother ",
+			expected: []string{"-v", "file.txt", "other"},
+		},
+		{
+			name:     "single quotes",
+			line:     `'a b' 'c\d'`,
+			expected: []string{"a b", `c\d`},
+		},
+		{
+			name:     "double quotes",
+			line:     `"a b" "c\"d" "e\f"`,
+			expected: []string{"a b", `c"d`, `e\f`},
+		},
+		{
+			name:     "backslash outside quotes",
+			line:     `a\ b`,
+			expected: []string{"a b"},
+		},
+		{
+			name:     "empty quoted argument",
+			line:     `'' x`,
+			expected: []string{"", "x"},
+		},
+		{
+			name: "unterminated quote",
+			line: `"a b`,
+			err:  ErrUnterminatedQuote,
+		},
+		{
+			name: "trailing backslash",
+			line: `a\`,
+			err:  ErrTrailingBackslash,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitArgs(tt.line)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("SplitArgs(%q) error = %v, want %v", tt.line, err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SplitArgs(%q) = %q, want %q", tt.line, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [*Scanner.ScanLineWithQuotes] marks the
+// positional arguments that were originally quoted.
+func TestScanLineWithQuotes(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:  []string{"-", "--"},
+		Separator: "--",
+	}
+
+	got, err := scanner.ScanLineWithQuotes(`-v "my file.txt" bare`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+		PositionalArgumentToken{Idx: 1, Value: "my file.txt", Quoted: true},
+		PositionalArgumentToken{Idx: 2, Value: "bare", Quoted: false},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanLineWithQuotes() = %#v, want %#v", got, expect)
+	}
+
+	if _, err := scanner.ScanLineWithQuotes(`'unterminated`); !errors.Is(err, ErrUnterminatedQuote) {
+		t.Errorf("ScanLineWithQuotes() error = %v, want %v", err, ErrUnterminatedQuote)
+	}
+}
+
+// This test ensures that [JoinContinuations] merges the arguments
+// ending with an unescaped backslash with the following ones.
+func TestJoinContinuations(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "two-part continuation",
+			args:     []string{"-v", `--msg=hello\`, "world", "x"},
+			expected: []string{"-v", "--msg=helloworld", "x"},
+		},
+		{
+			name:     "three-part continuation",
+			args:     []string{`a\`, `b\`, "c"},
+			expected: []string{"abc"},
+		},
+		{
+			name:     "doubled backslash",
+			args:     []string{`a\\`, "b"},
+			expected: []string{`a\\`, "b"},
+		},
+		{
+			name:     "trailing backslash in the last argument",
+			args:     []string{"a", `b\`},
+			expected: []string{"a", `b\`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := JoinContinuations(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("JoinContinuations(%q) = %q, want %q", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// stream.go - Streaming command line scanning.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+// ScanChan is like [*Scanner.Scan] but emits the tokens on a channel, which
+// is buffered to contain all the tokens and already closed, therefore the
+// caller may stop receiving at any time without leaking resources.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanChan(args []string) <-chan Token {
+	tokens := sx.Scan(args)
+	ch := make(chan Token, len(tokens))
+	for _, token := range tokens {
+		ch <- token
+	}
+	close(ch)
+	return ch
+}
+
+// StreamScanner scans command line arguments arriving in batches (e.g., in
+// a long-running server), preserving the token indexes and the scanning state,
+// such as whether we have seen the separator, across batches.
+//
+// An option only consumes values from its own batch, therefore an option
+// with [Scanner.OptionsWithArity] ending a batch has the MissingValue field
+// set. We do not emit the [EndToken], since we cannot know which batch is
+// the last one. A [StreamScanner] is not safe to use concurrently.
+type StreamScanner struct {
+	// sx is the underlying scanner.
+	sx *Scanner
+
+	// offset is the index of the first argument of the next batch.
+	offset int
+
+	// state is the state persisting across batches.
+	state scanState
+}
+
+// NewStreamScanner returns a [*StreamScanner] using the [*Scanner] configuration.
+//
+// The [*Scanner] MUST NOT be modified while using the [*StreamScanner].
+func (sx *Scanner) NewStreamScanner() *StreamScanner {
+	return &StreamScanner{sx: sx}
+}
+
+// ScanBatch scans the next batch of arguments and returns its tokens, whose
+// indexes continue from the previous batches. For example, feeding "--a", then
+// "b" and "--", then "c" produces tokens with Idx 0, 1, 2, and 3, where "c"
+// is a positional argument because the separator took effect before it.
+func (ss *StreamScanner) ScanBatch(args []string) []Token {
+	tokens, _ := ss.sx.scanArgs(args, &ss.state)
+	for idx, token := range tokens {
+		tokens[idx] = shiftIndex(token, ss.offset)
+	}
+	ss.offset += len(args)
+	return tokens
+}
+
+// TokenReader reads the tokens produced by [*Scanner.Scan] one at a time,
+// letting the consumer control the pacing (e.g., when integrating with other
+// pull-based streams). We scan each argument, along with the values it consumes,
+// only when needed, and we emit the arguments following the separator without
+// scanning them. A [TokenReader] is not safe to use concurrently.
+type TokenReader struct {
+	// sx is the underlying scanner.
+	sx *Scanner
+
+	// args contains the arguments to scan.
+	args []string
+
+	// next is the index of the next argument to scan.
+	next int
+
+	// pending contains the scanned tokens not yet returned.
+	pending []Token
+
+	// ended indicates that we have returned the [EndToken], if needed.
+	ended bool
+
+	// state is the scanning state.
+	state scanState
+}
+
+// NewTokenReader returns a [*TokenReader] reading the tokens of args.
+//
+// The [*Scanner] MUST NOT be modified while using the [*TokenReader].
+func (sx *Scanner) NewTokenReader(args []string) *TokenReader {
+	return &TokenReader{sx: sx, args: args}
+}
+
+// Next returns the next token and true, or nil and false when there are no more tokens.
+func (tr *TokenReader) Next() (Token, bool) {
+	for len(tr.pending) <= 0 {
+		switch {
+		case tr.next >= len(tr.args):
+			if tr.sx.EmitEndToken && !tr.ended {
+				tr.ended = true
+				return EndToken{Idx: len(tr.args)}, true
+			}
+			return nil, false
+
+		case tr.state.stopped && tr.sx.CaptureRemainderAsRaw:
+			tr.pending = tr.sx.appendRemainder(tr.pending, tr.args, tr.next, &tr.state)
+			tr.next = len(tr.args)
+
+		case tr.state.stopped:
+			token := tr.sx.newRemainderToken(&tr.state, tr.next, tr.args[tr.next])
+			tr.next++
+			return token, true
+
+		default:
+			tr.pending, _, tr.next = tr.sx.scanRange(tr.args, tr.next, tr.next+1, &tr.state)
+		}
+	}
+	token := tr.pending[0]
+	tr.pending = tr.pending[1:]
+	return token, true
+}
@@ -0,0 +1,248 @@
+// stream_test.go - Tests for streaming command line scanning.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// This test ensures that [*Scanner.ScanChan] emits the same
+// tokens emitted by [*Scanner.Scan].
+func TestScanChan(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:  []string{"-", "--"},
+		Separator: "--",
+	}
+	args := []string{"-v", "--file", "a.txt", "--", "-b"}
+
+	var got []Token
+	for token := range scanner.ScanChan(args) {
+		got = append(got, token)
+	}
+
+	expect := scanner.Scan(args)
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanChan() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [*Scanner.ScanChan] does not start any
+// goroutine, therefore a consumer stopping early leaks nothing.
+func TestScanChanNoLeak(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-"}}
+	before := runtime.NumGoroutine()
+
+	for range 16 {
+		for range scanner.ScanChan([]string{"-v", "a.txt"}) {
+			break
+		}
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("goroutines: got %d, want at most %d", after, before)
+	}
+}
+
+// This test ensures that [*StreamScanner.ScanBatch] preserves the indexes
+// and the separator state across batches.
+func TestStreamScannerScanBatch(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:        []string{"-", "--"},
+		Separator:       "--",
+		PositionalNames: []string{"FIRST", "SECOND"},
+		EmitEndToken:    true,
+	}
+	stream := scanner.NewStreamScanner()
+
+	batches := [][]string{{"--a"}, {"b", "--"}, {"-c"}, {}, {"d"}}
+	expect := [][]Token{
+		{
+			OptionToken{Idx: 0, Prefix: "--", Name: "a"},
+		},
+		{
+			PositionalArgumentToken{Idx: 1, Value: "b", Name: "FIRST"},
+			OptionsArgumentsSeparatorToken{Idx: 2, Separator: "--"},
+		},
+		{
+			PositionalArgumentToken{Idx: 3, Value: "-c"},
+		},
+		{},
+		{
+			PositionalArgumentToken{Idx: 4, Value: "d"},
+		},
+	}
+
+	for idx, batch := range batches {
+		got := stream.ScanBatch(batch)
+		if !reflect.DeepEqual(got, expect[idx]) {
+			t.Errorf("ScanBatch(%q) = %#v, want %#v", batch, got, expect[idx])
+		}
+	}
+}
+
+// This test ensures that [*StreamScanner.ScanBatch] preserves the
+// positional names and only treats the first argument as the verb.
+func TestStreamScannerScanBatchState(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:        []string{"-"},
+		PositionalNames: []string{"FIRST", "SECOND"},
+		FirstArgIsVerb:  true,
+	}
+	stream := scanner.NewStreamScanner()
+
+	var got []Token
+	for _, batch := range [][]string{{}, {"run", "a"}, {"b", "-v"}, {"c"}} {
+		got = append(got, stream.ScanBatch(batch)...)
+	}
+
+	expect := []Token{
+		SubcommandToken{Idx: 0, Name: "run"},
+		PositionalArgumentToken{Idx: 1, Value: "a", Name: "FIRST"},
+		PositionalArgumentToken{Idx: 2, Value: "b", Name: "SECOND"},
+		OptionToken{Idx: 3, Prefix: "-", Name: "v"},
+		PositionalArgumentToken{Idx: 4, Value: "c"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanBatch() = %#v, want %#v", got, expect)
+	}
+}
+
+// readAll drives [*TokenReader.Next] to exhaustion.
+func readAll(tr *TokenReader) []Token {
+	var tokens []Token
+	for {
+		token, ok := tr.Next()
+		if !ok {
+			return tokens
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+// This test ensures that [*TokenReader] reads the same tokens as [*Scanner.Scan].
+func TestTokenReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		scanner *Scanner
+		args    []string
+	}{
+		{
+			name: "GNU style",
+			scanner: &Scanner{
+				Prefixes:           []string{"-", "--"},
+				Separator:          "--",
+				BundleShortOptions: true,
+				OptionsWithArity:   map[string]int{"file": 1, "o": 1},
+				PositionalNames:    []string{"input"},
+				EmitEndToken:       true,
+			},
+			args: []string{"-vo", "out", "--file", "x", "in", "-ab", "--", "-c", "last"},
+		},
+		{
+			name: "raw remainder after a terminating option",
+			scanner: &Scanner{
+				Prefixes:              []string{"-"},
+				TerminatingOptions:    map[string]bool{"e": true},
+				CaptureRemainderAsRaw: true,
+			},
+			args: []string{"-v", "-e", "ls", "-la"},
+		},
+		{
+			name: "verb and comment",
+			scanner: &Scanner{
+				Prefixes:       []string{"-"},
+				FirstArgIsVerb: true,
+				CommentPrefix:  "#",
+				EmitEndToken:   true,
+			},
+			args: []string{"-status", "-v", "file", "#", "-x"},
+		},
+		{
+			name: "toggling separator",
+			scanner: &Scanner{
+				Prefixes:          []string{"-"},
+				Separator:         "--",
+				ToggleOnSeparator: true,
+			},
+			args: []string{"-a", "--", "-b", "--", "-c"},
+		},
+		{
+			name:    "no arguments",
+			scanner: &Scanner{Prefixes: []string{"-"}, EmitEndToken: true},
+			args:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readAll(tt.scanner.NewTokenReader(tt.args))
+			expect := tt.scanner.Scan(tt.args)
+			if len(got) != len(expect) || (len(got) > 0 && !reflect.DeepEqual(got, expect)) {
+				t.Errorf("TokenReader(%q) = %#v, want %#v", tt.args, got, expect)
+			}
+			if _, ok := tt.scanner.NewTokenReader(tt.args).Next(); ok != (len(expect) > 0) {
+				t.Errorf("TokenReader(%q).Next() ok = %v, want %v", tt.args, ok, len(expect) > 0)
+			}
+		})
+	}
+}
+
+// This test ensures that [*TokenReader] emits the arguments following the
+// separator one at a time, as positional arguments, without scanning them.
+func TestTokenReaderSeparator(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-", "--"}, Separator: "--"}
+	args := []string{"-v", "--", "-x", "--"}
+	tr := scanner.NewTokenReader(args)
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+		OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+	}
+	for _, want := range expect {
+		if got, ok := tr.Next(); !ok || !reflect.DeepEqual(got, want) {
+			t.Fatalf("Next() = %#v, %v, want %#v, true", got, ok, want)
+		}
+	}
+	if tr.next != 2 || len(tr.pending) != 0 {
+		t.Fatalf("after the separator, next = %d and pending = %#v, want 2 and none", tr.next, tr.pending)
+	}
+
+	expect = []Token{
+		PositionalArgumentToken{Idx: 2, Value: "-x"},
+		PositionalArgumentToken{Idx: 3, Value: "--"},
+	}
+	for _, want := range expect {
+		if got, ok := tr.Next(); !ok || !reflect.DeepEqual(got, want) {
+			t.Fatalf("Next() = %#v, %v, want %#v, true", got, ok, want)
+		}
+	}
+	if got, ok := tr.Next(); ok {
+		t.Errorf("Next() = %#v, true, want nil, false", got)
+	}
+}
+
+// This test ensures that [*TokenReader] sorts the prefixes once
+// rather than for each argument.
+func TestTokenReaderSortsPrefixesOnce(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-", "--", "+"}}
+	tr := scanner.NewTokenReader([]string{"-a", "--bee", "+c", "d"})
+
+	var sorted []string
+	for range 4 {
+		if _, ok := tr.Next(); !ok {
+			t.Fatal("Next() = nil, false, want a token")
+		}
+		if sorted == nil {
+			sorted = tr.state.prefixes
+		}
+		if &tr.state.prefixes[0] != &sorted[0] {
+			t.Fatalf("prefixes = %q, sorted again after %q", tr.state.prefixes, sorted)
+		}
+	}
+	if expect := []string{"--", "+", "-"}; !reflect.DeepEqual(sorted, expect) {
+		t.Errorf("prefixes = %q, want %q", sorted, expect)
+	}
+}
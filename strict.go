@@ -0,0 +1,96 @@
+// strict.go - Strict command line scanning.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrExtraPrefixRun indicates that an argument starts with more repeated prefix
+// characters than any configured prefix when using [Scanner.GreedyPrefixRun].
+var ErrExtraPrefixRun = errors.New("leading prefix run does not match any configured prefix")
+
+// ErrPrefixOnly indicates that an argument consists of a prefix only
+// when using [Scanner.ErrorOnPrefixOnly].
+var ErrPrefixOnly = errors.New("argument consists of a prefix only")
+
+// ErrUnknownPrefix indicates that an argument starts with a prefix listed
+// in [Scanner.DetectPrefixes] but not in [Scanner.Prefixes].
+var ErrUnknownPrefix = errors.New("argument starts with an unconfigured prefix")
+
+// ErrInvalidEnumValue indicates that the value of an option
+// is not among the values listed in [Scanner.OptionEnums].
+var ErrInvalidEnumValue = errors.New("option value is not allowed")
+
+// ErrTooManyOccurrences indicates that an option appears more
+// times than allowed by the [Scanner.OptionMaxOccurrences].
+var ErrTooManyOccurrences = errors.New("option appears too many times")
+
+// ScanError is the error describing a malformed argument.
+type ScanError struct {
+	// Idx is the position in the original command line arguments.
+	Idx int
+
+	// Arg is the malformed argument.
+	Arg string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (err *ScanError) Error() string {
+	return fmt.Sprintf("argument #%d (%q): %s", err.Idx, err.Arg, err.Err.Error())
+}
+
+// Unwrap allows using [errors.Is] with the underlying error.
+func (err *ScanError) Unwrap() error {
+	return err.Err
+}
+
+// ScanStrict is like [*Scanner.Scan] but also returns a [*ScanError]
+// describing the first malformed argument, if any.
+//
+// The returned tokens are the same that [*Scanner.Scan] would return.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanStrict(args []string) ([]Token, error) {
+	tokens, errs := sx.scan(args)
+	if len(errs) > 0 {
+		return tokens, errs[0]
+	}
+	return tokens, nil
+}
+
+// checkEnum returns an error wrapping [ErrInvalidEnumValue] when the value
+// of tk is not among the [Scanner.OptionEnums] for its name, or nil.
+func (sx *Scanner) checkEnum(tk OptionToken) error {
+	allowed, found := sx.OptionEnums[tk.Name]
+	if !found || !tk.hasValue() || slices.Contains(allowed, tk.Value) {
+		return nil
+	}
+	return fmt.Errorf("%w: option %q has value %q, want one of %q",
+		ErrInvalidEnumValue, tk.Name, tk.Value, allowed)
+}
+
+// checkOccurrences counts the occurrences of tk into the state and returns an
+// error wrapping [ErrTooManyOccurrences] when they exceed the [Scanner.OptionMaxOccurrences]
+// for its name, or nil.
+func (sx *Scanner) checkOccurrences(tk OptionToken, state *scanState) error {
+	limit, found := sx.OptionMaxOccurrences[tk.Name]
+	if !found {
+		return nil
+	}
+	if state.occurrences == nil {
+		state.occurrences = make(map[string]int)
+	}
+	state.occurrences[tk.Name] += max(tk.Count, 1)
+	if count := state.occurrences[tk.Name]; count > limit {
+		return fmt.Errorf("%w: option %q appears %d times, want at most %d",
+			ErrTooManyOccurrences, tk.Name, count, limit)
+	}
+	return nil
+}
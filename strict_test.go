@@ -0,0 +1,309 @@
+// strict_test.go - Tests for strict command line scanning.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [Scanner.GreedyPrefixRun] matches the prefix
+// equal to the whole leading run and rejects extra leading dashes.
+func TestScanStrictGreedyPrefixRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []Token
+		err      error
+	}{
+		{
+			name: "single dash",
+			args: []string{"-x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "x"},
+			},
+		},
+		{
+			name: "double dash",
+			args: []string{"--x"},
+			expected: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "x"},
+			},
+		},
+		{
+			name: "triple dash",
+			args: []string{"---x"},
+			expected: []Token{
+				PositionalArgumentToken{Idx: 0, Value: "---x"},
+			},
+			err: ErrExtraPrefixRun,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:        []string{"-", "--"},
+				GreedyPrefixRun: true,
+			}
+			got, err := scanner.ScanStrict(tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ScanStrict(%q) error = %v, want %v", tt.args, err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ScanStrict(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that without [Scanner.GreedyPrefixRun] extra leading
+// dashes become part of the option name and are not an error.
+func TestScanStrictWithoutGreedyPrefixRun(t *testing.T) {
+	scanner := &Scanner{Prefixes: []string{"-", "--"}}
+	got, err := scanner.ScanStrict([]string{"---x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Token{OptionToken{Idx: 0, Prefix: "--", Name: "-x"}}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ScanStrict() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [*ScanError] describes the malformed argument.
+func TestScanErrorMessage(t *testing.T) {
+	err := &ScanError{Idx: 2, Arg: "---x", Err: ErrExtraPrefixRun}
+	expect := `argument #2 ("---x"): leading prefix run does not match any configured prefix`
+	if err.Error() != expect {
+		t.Errorf("ScanError.Error() = %q, want %q", err.Error(), expect)
+	}
+}
+
+// This test ensures that [Scanner.ErrorOnPrefixOnly] flags bare
+// prefixes except for the separator and the stdin marker.
+func TestScanStrictErrorOnPrefixOnly(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  error
+	}{
+		{
+			name: "bare plus",
+			args: []string{"+"},
+			err:  ErrPrefixOnly,
+		},
+		{
+			name: "stdin marker",
+			args: []string{"-"},
+			err:  nil,
+		},
+		{
+			name: "separator",
+			args: []string{"--", "+"},
+			err:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:          []string{"-", "--", "+"},
+				Separator:         "--",
+				StdinMarker:       "-",
+				ErrorOnPrefixOnly: true,
+			}
+			_, err := scanner.ScanStrict(tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ScanStrict(%q) error = %v, want %v", tt.args, err, tt.err)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.DetectPrefixes] flags arguments
+// starting with a prefix-looking sequence that is not configured.
+func TestScanStrictDetectPrefixes(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  error
+	}{
+		{
+			name: "unconfigured percent",
+			args: []string{"%flag"},
+			err:  ErrUnknownPrefix,
+		},
+		{
+			name: "em dash typo",
+			args: []string{"—flag"},
+			err:  ErrUnknownPrefix,
+		},
+		{
+			name: "configured prefix",
+			args: []string{"-flag"},
+			err:  nil,
+		},
+		{
+			name: "percent alone",
+			args: []string{"%"},
+			err:  nil,
+		},
+		{
+			name: "after the separator",
+			args: []string{"--", "%flag"},
+			err:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:       []string{"-", "--"},
+				Separator:      "--",
+				DetectPrefixes: []string{"-", "%", "—"},
+			}
+			tokens, err := scanner.ScanStrict(tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ScanStrict(%q) error = %v, want %v", tt.args, err, tt.err)
+			}
+			if !reflect.DeepEqual(tokens, scanner.Scan(tt.args)) {
+				t.Errorf("ScanStrict(%q) = %#v, want the same tokens as Scan", tt.args, tokens)
+			}
+		})
+	}
+}
+
+// This test ensures that [Scanner.OptionEnums] rejects
+// values not listed among the allowed values.
+func TestScanStrictOptionEnums(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  error
+	}{
+		{
+			name: "allowed value",
+			args: []string{"--color=auto", "file"},
+			err:  nil,
+		},
+		{
+			name: "allowed consumed value",
+			args: []string{"--level", "high"},
+			err:  nil,
+		},
+		{
+			name: "invalid value",
+			args: []string{"--color=sometimes"},
+			err:  ErrInvalidEnumValue,
+		},
+		{
+			name: "invalid consumed value",
+			args: []string{"--level", "extreme"},
+			err:  ErrInvalidEnumValue,
+		},
+		{
+			name: "option without enum",
+			args: []string{"--file=anything"},
+			err:  nil,
+		},
+		{
+			name: "option without value",
+			args: []string{"--color"},
+			err:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:         []string{"-", "--"},
+				Separator:        "--",
+				SplitValues:      true,
+				OptionsWithArity: map[string]int{"level": 1},
+				OptionEnums: map[string][]string{
+					"color": {"always", "never", "auto"},
+					"level": {"low", "high"},
+				},
+			}
+			tokens, err := scanner.ScanStrict(tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ScanStrict(%q) error = %v, want %v", tt.args, err, tt.err)
+			}
+			if !reflect.DeepEqual(tokens, scanner.Scan(tt.args)) {
+				t.Errorf("ScanStrict(%q) = %#v, want the same tokens as Scan", tt.args, tokens)
+			}
+		})
+	}
+}
+
+// This test ensures that the [ErrInvalidEnumValue] error
+// names the option, the bad value, and the allowed values.
+func TestScanStrictOptionEnumsMessage(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:    []string{"--"},
+		SplitValues: true,
+		OptionEnums: map[string][]string{"color": {"always", "never"}},
+	}
+	_, err := scanner.ScanStrict([]string{"-x", "--color=blue"})
+	expect := `argument #1 ("--color=blue"): option value is not allowed: ` +
+		`option "color" has value "blue", want one of ["always" "never"]`
+	if err == nil || err.Error() != expect {
+		t.Errorf("ScanStrict() error = %v, want %s", err, expect)
+	}
+}
+
+// This test ensures that [Scanner.OptionMaxOccurrences] reports
+// each occurrence exceeding the limit at its own index.
+func TestScanStrictOptionMaxOccurrences(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		idx  int
+		err  error
+	}{
+		{
+			name: "within the limit",
+			args: []string{"--tag", "--config", "--tag"},
+			err:  nil,
+		},
+		{
+			name: "exceeding the limit",
+			args: []string{"--config", "--tag", "--config"},
+			idx:  2,
+			err:  ErrTooManyOccurrences,
+		},
+		{
+			name: "counted occurrences",
+			args: []string{"-vv", "-v"},
+			idx:  1,
+			err:  ErrTooManyOccurrences,
+		},
+		{
+			name: "unconstrained option",
+			args: []string{"--name", "--name", "--name"},
+			err:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:             []string{"-", "--"},
+				Separator:            "--",
+				CountableFlags:       map[string]bool{"v": true},
+				OptionMaxOccurrences: map[string]int{"config": 1, "tag": 2, "v": 2},
+			}
+			_, err := scanner.ScanStrict(tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ScanStrict(%q) error = %v, want %v", tt.args, err, tt.err)
+			}
+			var serr *ScanError
+			if err != nil && (!errors.As(err, &serr) || serr.Idx != tt.idx) {
+				t.Errorf("ScanStrict(%q) error = %v, want index %d", tt.args, err, tt.idx)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// suggest.go - Suggesting corrections for mistyped options.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SuggestCorrection returns candidate corrections for an option with a single-byte
+// prefix and a multi-character name (e.g., "-verbose"), which is often a typo
+// for a long option or a bundle of short options. The candidates are:
+//
+//  1. the long form (e.g., "--verbose") when the name is in knownLong
+//
+//  2. the debundled short options (e.g., "-v -e -r") when each
+//     character of the name is in knownShort
+//
+// We return nil for any other option. Use the candidates to produce
+// "did you mean" messages.
+func SuggestCorrection(tk OptionToken, knownLong, knownShort map[string]bool) []string {
+	if len(tk.Prefix) != 1 || utf8.RuneCountInString(tk.Name) <= 1 {
+		return nil
+	}
+	var candidates []string
+	if knownLong[tk.Name] {
+		candidates = append(candidates, "--"+tk.Name)
+	}
+	shorts := make([]string, 0, len(tk.Name))
+	for _, ch := range tk.Name {
+		if !knownShort[string(ch)] {
+			return candidates
+		}
+		shorts = append(shorts, tk.Prefix+string(ch))
+	}
+	return append(candidates, strings.Join(shorts, " "))
+}
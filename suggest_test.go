@@ -0,0 +1,62 @@
+// suggest_test.go - Tests for suggesting corrections for mistyped options.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [SuggestCorrection] suggests the long
+// form and the debundled short options when applicable.
+func TestSuggestCorrection(t *testing.T) {
+	knownLong := map[string]bool{"verbose": true, "vx": true}
+	knownShort := map[string]bool{"v": true, "x": true, "z": true}
+
+	tests := []struct {
+		name     string
+		token    OptionToken
+		expected []string
+	}{
+		{
+			name:     "correctable to a long option",
+			token:    OptionToken{Prefix: "-", Name: "verbose"},
+			expected: []string{"--verbose"},
+		},
+		{
+			name:     "correctable to a bundle",
+			token:    OptionToken{Prefix: "-", Name: "vxz"},
+			expected: []string{"-v -x -z"},
+		},
+		{
+			name:     "correctable to both",
+			token:    OptionToken{Prefix: "-", Name: "vx"},
+			expected: []string{"--vx", "-v -x"},
+		},
+		{
+			name:     "not correctable",
+			token:    OptionToken{Prefix: "-", Name: "unknown"},
+			expected: nil,
+		},
+		{
+			name:     "already a long option",
+			token:    OptionToken{Prefix: "--", Name: "verbose"},
+			expected: nil,
+		},
+		{
+			name:     "short option",
+			token:    OptionToken{Prefix: "-", Name: "v"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SuggestCorrection(tt.token, knownLong, knownShort)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SuggestCorrection() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
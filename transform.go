@@ -0,0 +1,75 @@
+// transform.go - Helpers for transforming scanned tokens.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import "slices"
+
+// ResolveAliases returns a copy of tokens where each [OptionToken] whose
+// Name is in aliases uses the aliased Name instead (e.g., "v" mapped to
+// "verbose"), so downstream code sees canonical names. The original name
+// is preserved into the RawName field. The prefix is not modified.
+func ResolveAliases(tokens []Token, aliases map[string]string) []Token {
+	output := make([]Token, 0, len(tokens))
+	for _, token := range tokens {
+		if tk, ok := token.(OptionToken); ok {
+			if name, found := aliases[tk.Name]; found {
+				tk.RawName, tk.Name = tk.Name, name
+				token = tk
+			}
+		}
+		output = append(output, token)
+	}
+	return output
+}
+
+// RewritePrefixes returns a copy of tokens where each [OptionToken] whose
+// Prefix is in mapping uses the mapped Prefix instead (e.g., "--" mapped to
+// "-"), leaving names and values intact. This allows converting between
+// command-line styles, since [Join] of the result produces the converted
+// command line (e.g., "--verbose --file=x" becomes "-verbose -file=x").
+func RewritePrefixes(tokens []Token, mapping map[string]string) []Token {
+	output := make([]Token, 0, len(tokens))
+	for _, token := range tokens {
+		if tk, ok := token.(OptionToken); ok {
+			if prefix, found := mapping[tk.Prefix]; found {
+				tk.Prefix = prefix
+				token = tk
+			}
+		}
+		output = append(output, token)
+	}
+	return output
+}
+
+// RescanPositionals re-scans the positional arguments following the separator
+// or, without a separator, the [TrailingPositionals], using the inner scanner,
+// which enables nested parsing using different styles. The returned tokens
+// contain the tokens preceding the re-scanned section unchanged, followed by
+// the tokens produced by the inner scanner, whose indexes refer to the original
+// arguments, followed by the final [EndToken], if any.
+func RescanPositionals(tokens []Token, inner *Scanner) []Token {
+	var end []Token
+	if count := len(tokens); count > 0 {
+		if _, ok := tokens[count-1].(EndToken); ok {
+			tokens, end = tokens[:count-1], tokens[count-1:]
+		}
+	}
+
+	start := len(tokens) - len(TrailingPositionals(tokens))
+	for idx, token := range tokens {
+		if _, ok := token.(OptionsArgumentsSeparatorToken); ok {
+			start = idx + 1
+			break
+		}
+	}
+
+	output := slices.Clone(tokens[:start])
+	if section := tokens[start:]; len(section) > 0 {
+		offset := section[0].Index()
+		for _, token := range inner.Scan(Join(section)) {
+			output = append(output, shiftIndex(token, offset))
+		}
+	}
+	return append(output, end...)
+}
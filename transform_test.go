@@ -0,0 +1,105 @@
+// transform_test.go - Tests for transforming scanned tokens.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [ResolveAliases] maps listed option names
+// and leaves everything else unchanged.
+func TestResolveAliases(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:  []string{"-", "--"},
+		Separator: "--",
+	}
+	tokens := scanner.Scan([]string{"-v", "--debug", "v"})
+
+	got := ResolveAliases(tokens, map[string]string{"v": "verbose"})
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "-", Name: "verbose", RawName: "v"},
+		OptionToken{Idx: 1, Prefix: "--", Name: "debug"},
+		PositionalArgumentToken{Idx: 2, Value: "v"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ResolveAliases() = %#v, want %#v", got, expect)
+	}
+}
+
+// This test ensures that [RewritePrefixes] converts a GNU-style
+// command line into a Go-style command line.
+func TestRewritePrefixes(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:    []string{"-", "--"},
+		Separator:   "--",
+		SplitValues: true,
+	}
+	args := []string{"--verbose", "--file=x", "-v", "--", "--raw"}
+	tokens := scanner.Scan(args)
+
+	got := RewritePrefixes(tokens, map[string]string{"--": "-"})
+
+	expect := []Token{
+		OptionToken{Idx: 0, Prefix: "-", Name: "verbose"},
+		OptionToken{Idx: 1, Prefix: "-", Name: "file", ValueSeparator: "=", Value: "x"},
+		OptionToken{Idx: 2, Prefix: "-", Name: "v"},
+		OptionsArgumentsSeparatorToken{Idx: 3, Separator: "--"},
+		PositionalArgumentToken{Idx: 4, Value: "--raw"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("RewritePrefixes() = %#v, want %#v", got, expect)
+	}
+
+	joined := Join(got)
+	expectArgs := []string{"-verbose", "-file=x", "-v", "--", "--raw"}
+	if !reflect.DeepEqual(joined, expectArgs) {
+		t.Errorf("Join() = %q, want %q", joined, expectArgs)
+	}
+}
+
+// This test ensures that [RescanPositionals] re-scans the section
+// following the separator using the inner scanner.
+func TestRescanPositionals(t *testing.T) {
+	outer := &Scanner{
+		Prefixes:     []string{"-", "--"},
+		Separator:    "--",
+		EmitEndToken: true,
+	}
+	inner := &Scanner{
+		Prefixes:  []string{"-", "--", "+"},
+		Separator: "--",
+	}
+
+	t.Run("after the separator", func(t *testing.T) {
+		tokens := outer.Scan([]string{"-v", "--", "+trace", "-x", "example.com"})
+		got := RescanPositionals(tokens, inner)
+		expect := []Token{
+			OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+			OptionsArgumentsSeparatorToken{Idx: 1, Separator: "--"},
+			OptionToken{Idx: 2, Prefix: "+", Name: "trace"},
+			OptionToken{Idx: 3, Prefix: "-", Name: "x"},
+			PositionalArgumentToken{Idx: 4, Value: "example.com"},
+			EndToken{Idx: 5},
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("RescanPositionals() = %#v, want %#v", got, expect)
+		}
+	})
+
+	t.Run("trailing positionals", func(t *testing.T) {
+		tokens := outer.Scan([]string{"-v", "+short", "example.com"})
+		got := RescanPositionals(tokens, inner)
+		expect := []Token{
+			OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+			OptionToken{Idx: 1, Prefix: "+", Name: "short"},
+			PositionalArgumentToken{Idx: 2, Value: "example.com"},
+			EndToken{Idx: 3},
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("RescanPositionals() = %#v, want %#v", got, expect)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+// until.go - Scanning until a stop condition.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+// ScanUntil is like [*Scanner.Scan] but stops after the first token for which
+// stop returns true, returning the tokens produced so far and the remaining
+// arguments verbatim, so that a parser can hand them off untouched (e.g., "parse
+// my options and give me the rest"). When stop matches an option, the returned
+// tokens also include its consumed values and the other options in the same
+// bundle. When stop never returns true, the rest is empty.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanUntil(args []string, stop func(Token) bool) (tokens []Token, rest []string) {
+	all := sx.Scan(args)
+	for idx, token := range all {
+		if !stop(token) {
+			continue
+		}
+		end := idx + 1
+		for end < len(all) && all[end].Index() == token.Index() {
+			if _, ok := all[end].(EndToken); ok {
+				break
+			}
+			end++
+		}
+		return all[:end], args[min(sx.tokenEnd(args, all[end-1]), len(args)):]
+	}
+	return all, nil
+}
+
+// tokenEnd returns the index of the argument following the token.
+func (sx *Scanner) tokenEnd(args []string, token Token) int {
+	switch tk := token.(type) {
+	case OptionToken:
+		return tk.Idx + 1 + tk.NameConsumed + tk.Consumed
+	case RawRemainderToken:
+		return tk.Idx + len(tk.Args)
+	case EndToken:
+		return tk.Idx
+	default:
+		return tk.Index() + 1
+	}
+}
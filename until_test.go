@@ -0,0 +1,117 @@
+// until_test.go - Tests for scanning until a stop condition.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// isOption returns a function returning whether a token is the named option.
+func isOption(name string) func(Token) bool {
+	return func(token Token) bool {
+		tk, ok := token.(OptionToken)
+		return ok && tk.Name == name
+	}
+}
+
+// This test ensures that [*Scanner.ScanUntil] stops after the matching
+// token and returns the remaining arguments verbatim.
+func TestScannerScanUntil(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		stop         func(Token) bool
+		expectTokens []Token
+		expectRest   []string
+	}{
+		{
+			name: "stop on an option",
+			args: []string{"-v", "--exec", "ls", "-la", "--", "x"},
+			stop: isOption("exec"),
+			expectTokens: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				OptionToken{Idx: 1, Prefix: "--", Name: "exec"},
+			},
+			expectRest: []string{"ls", "-la", "--", "x"},
+		},
+		{
+			name: "stop on an option with a consumed value",
+			args: []string{"--file", "x", "-v", "y"},
+			stop: isOption("file"),
+			expectTokens: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", Value: "x", Consumed: 1},
+			},
+			expectRest: []string{"-v", "y"},
+		},
+		{
+			name: "stop on an extension option",
+			args: []string{"-W", "file", "x", "y"},
+			stop: isOption("file"),
+			expectTokens: []Token{
+				OptionToken{Idx: 0, Prefix: "--", Name: "file", Value: "x", Consumed: 1, Extension: "-W", NameConsumed: 1},
+			},
+			expectRest: []string{"y"},
+		},
+		{
+			name: "stop within a bundle",
+			args: []string{"-ab", "-c"},
+			stop: isOption("a"),
+			expectTokens: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "a"},
+				OptionToken{Idx: 0, SubIdx: 1, Prefix: "-", Name: "b"},
+			},
+			expectRest: []string{"-c"},
+		},
+		{
+			name: "never stop",
+			args: []string{"-v", "x"},
+			stop: isOption("exec"),
+			expectTokens: []Token{
+				OptionToken{Idx: 0, Prefix: "-", Name: "v"},
+				PositionalArgumentToken{Idx: 1, Value: "x"},
+				EndToken{Idx: 2},
+			},
+			expectRest: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:           []string{"-", "--"},
+				Separator:          "--",
+				BundleShortOptions: true,
+				OptionsWithArity:   map[string]int{"file": 1},
+				WExtensionOption:   "W",
+				EmitEndToken:       true,
+			}
+			tokens, rest := scanner.ScanUntil(tt.args, tt.stop)
+			if !reflect.DeepEqual(tokens, tt.expectTokens) {
+				t.Errorf("ScanUntil(%q) tokens = %#v, want %#v", tt.args, tokens, tt.expectTokens)
+			}
+			if !reflect.DeepEqual(rest, tt.expectRest) {
+				t.Errorf("ScanUntil(%q) rest = %q, want %q", tt.args, rest, tt.expectRest)
+			}
+		})
+	}
+}
+
+// This test ensures that [*Scanner.ScanUntil] skips the name of an option
+// following the [Scanner.WExtensionOption] when stripping invisibles.
+func TestScannerScanUntilExtensionWithInvisibles(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:               []string{"-", "--"},
+		StripLeadingInvisibles: true,
+		WExtensionOption:       "W",
+	}
+	args := []string{"\uFEFF-W", "foo", "bar"}
+
+	_, rest := scanner.ScanUntil(args, isOption("foo"))
+
+	expect := []string{"bar"}
+	if !reflect.DeepEqual(rest, expect) {
+		t.Errorf("ScanUntil(%q) rest = %q, want %q", args, rest, expect)
+	}
+}
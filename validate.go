@@ -0,0 +1,133 @@
+// validate.go - Helpers for validating scanned tokens.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import "strings"
+
+// DuplicateOptions returns each occurrence, after the first one, of the
+// options whose Name appears more than once and that are not repeatable.
+//
+// Parsers use this to produce "flag specified multiple times" errors.
+func DuplicateOptions(tokens []Token, repeatable map[string]bool) []OptionToken {
+	var duplicates []OptionToken
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		tk, ok := token.(OptionToken)
+		if !ok || repeatable[tk.Name] {
+			continue
+		}
+		if seen[tk.Name] {
+			duplicates = append(duplicates, tk)
+		}
+		seen[tk.Name] = true
+	}
+	return duplicates
+}
+
+// MissingRequired returns the names in required that are not the Name
+// of any [OptionToken], in the same order in which they appear in required.
+//
+// Since we compare Name, options must be scanned with [Scanner.SplitValues]
+// or [Scanner.OptionsWithArity] for options with values to match.
+func MissingRequired(tokens []Token, required []string) []string {
+	present := make(map[string]bool)
+	for _, token := range tokens {
+		if tk, ok := token.(OptionToken); ok {
+			present[tk.Name] = true
+		}
+	}
+	var missing []string
+	for _, name := range required {
+		if !present[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// ConflictingOptions returns, for each group of mutually exclusive option
+// names in which more than one member is present, the present members in
+// the same order in which they appear in the group. Groups without a
+// conflict do not contribute to the result.
+//
+// Parsers use this to produce "--quiet and --verbose are mutually exclusive" errors.
+func ConflictingOptions(tokens []Token, groups [][]string) [][]string {
+	present := make(map[string]bool)
+	for _, token := range tokens {
+		if tk, ok := token.(OptionToken); ok {
+			present[tk.Name] = true
+		}
+	}
+	var conflicts [][]string
+	for _, group := range groups {
+		var members []string
+		for _, name := range group {
+			if present[name] {
+				members = append(members, name)
+			}
+		}
+		if len(members) > 1 {
+			conflicts = append(conflicts, members)
+		}
+	}
+	return conflicts
+}
+
+// OptionsAfterPositionals returns the options following at least one
+// [PositionalArgumentToken] and preceding the separator (e.g., "-b" in
+// "-a file -b"), allowing tools requiring all the options before the
+// positional arguments to enforce such ordering or warn about it.
+func OptionsAfterPositionals(tokens []Token) []OptionToken {
+	var (
+		misplaced  []OptionToken
+		positional bool
+	)
+	for _, token := range tokens {
+		switch tk := token.(type) {
+		case OptionsArgumentsSeparatorToken:
+			return misplaced
+		case PositionalArgumentToken:
+			positional = true
+		case OptionToken:
+			if positional {
+				misplaced = append(misplaced, tk)
+			}
+		}
+	}
+	return misplaced
+}
+
+// RiskyPositionals returns the positional arguments whose Value contains shell
+// metacharacters (i.e., ";", "|", "&", "$", "`", "<", ">", and newlines), which
+// may be risky to pass to a subprocess through a shell (e.g., "x;rm -rf /").
+// We also check each argument of a [RawRemainderToken] (e.g., a wrapped command)
+// and each [AssignmentToken], which we report as a [PositionalArgumentToken]
+// with the Idx and the Value of the original argument.
+//
+// The result is advisory: it does not prevent shell injection, which requires
+// avoiding the shell or quoting (see [ShellQuote]).
+func RiskyPositionals(tokens []Token) []PositionalArgumentToken {
+	var risky []PositionalArgumentToken
+	check := func(tk PositionalArgumentToken) {
+		if strings.ContainsAny(tk.Value, shellRiskyChars) {
+			risky = append(risky, tk)
+		}
+	}
+	for _, token := range tokens {
+		switch tk := token.(type) {
+		case PositionalArgumentToken:
+			check(tk)
+		case RawRemainderToken:
+			for idx, arg := range tk.Args {
+				check(PositionalArgumentToken{Idx: tk.Idx + idx, Value: arg})
+			}
+		case AssignmentToken:
+			check(PositionalArgumentToken{Idx: tk.Idx, Value: tk.String()})
+		}
+	}
+	return risky
+}
+
+// shellRiskyChars contains the characters making positional arguments risky.
+const shellRiskyChars = ";|&$`<>\n\r"
@@ -0,0 +1,243 @@
+// validate_test.go - Tests for validating scanned tokens.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [DuplicateOptions] only reports
+// duplicated options that are not repeatable.
+func TestDuplicateOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []OptionToken
+	}{
+		{
+			name: "duplicated non-repeatable option",
+			args: []string{"--output", "-v", "--output"},
+			expected: []OptionToken{
+				{Idx: 2, Prefix: "--", Name: "output"},
+			},
+		},
+		{
+			name:     "duplicated repeatable option",
+			args:     []string{"-v", "-v", "-v"},
+			expected: nil,
+		},
+		{
+			name:     "unique options",
+			args:     []string{"--output", "-v", "--input"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:  []string{"-", "--"},
+				Separator: "--",
+			}
+			got := DuplicateOptions(scanner.Scan(tt.args), map[string]bool{"v": true})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("DuplicateOptions() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [MissingRequired] reports the
+// required options not present in the stream.
+func TestMissingRequired(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "all present",
+			args:     []string{"--input", "a", "--output", "b"},
+			expected: nil,
+		},
+		{
+			name:     "one missing",
+			args:     []string{"--input", "a"},
+			expected: []string{"output"},
+		},
+		{
+			name:     "present with values",
+			args:     []string{"--input=a", "--output=b"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:         []string{"-", "--"},
+				SplitValues:      true,
+				OptionsWithArity: map[string]int{"input": 1, "output": 1},
+			}
+			got := MissingRequired(scanner.Scan(tt.args), []string{"input", "output"})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("MissingRequired() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [ConflictingOptions] reports the groups
+// of mutually exclusive options with more than one member present.
+func TestConflictingOptions(t *testing.T) {
+	groups := [][]string{
+		{"quiet", "verbose"},
+		{"json", "yaml", "text"},
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected [][]string
+	}{
+		{
+			name:     "conflict",
+			args:     []string{"--verbose", "--quiet"},
+			expected: [][]string{{"quiet", "verbose"}},
+		},
+		{
+			name:     "no conflict",
+			args:     []string{"--verbose", "--json"},
+			expected: nil,
+		},
+		{
+			name:     "multiple independent groups",
+			args:     []string{"--quiet", "--text", "--verbose", "--json"},
+			expected: [][]string{{"quiet", "verbose"}, {"json", "text"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{Prefixes: []string{"-", "--"}}
+			got := ConflictingOptions(scanner.Scan(tt.args), groups)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ConflictingOptions() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [OptionsAfterPositionals] reports the
+// options following a positional argument before the separator.
+func TestOptionsAfterPositionals(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []OptionToken
+	}{
+		{
+			name: "option after positional",
+			args: []string{"-a", "file", "-b", "--c"},
+			expected: []OptionToken{
+				{Idx: 2, Prefix: "-", Name: "b"},
+				{Idx: 3, Prefix: "--", Name: "c"},
+			},
+		},
+		{
+			name:     "options before positionals",
+			args:     []string{"-a", "-b", "file", "other"},
+			expected: nil,
+		},
+		{
+			name:     "positionals after the separator",
+			args:     []string{"-a", "--", "file", "-b"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:  []string{"-", "--"},
+				Separator: "--",
+			}
+			got := OptionsAfterPositionals(scanner.Scan(tt.args))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("OptionsAfterPositionals() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [RiskyPositionals] flags the positional
+// arguments containing shell metacharacters.
+func TestRiskyPositionals(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []PositionalArgumentToken
+	}{
+		{
+			name: "command injection",
+			args: []string{"-v", "x;rm -rf /", "safe.txt"},
+			expected: []PositionalArgumentToken{
+				{Idx: 1, Value: "x;rm -rf /"},
+			},
+		},
+		{
+			name: "other metacharacters",
+			args: []string{"a|b", "a&b", "$HOME", "`id`", "a\nb", "a>b"},
+			expected: []PositionalArgumentToken{
+				{Idx: 0, Value: "a|b"},
+				{Idx: 1, Value: "a&b"},
+				{Idx: 2, Value: "$HOME"},
+				{Idx: 3, Value: "`id`"},
+				{Idx: 4, Value: "a\nb"},
+				{Idx: 5, Value: "a>b"},
+			},
+		},
+		{
+			name:     "safe filenames and options",
+			args:     []string{"--exec=a;b", "my file.txt", "dir/file-1.txt"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &Scanner{
+				Prefixes:  []string{"-", "--"},
+				Separator: "--",
+			}
+			got := RiskyPositionals(scanner.Scan(tt.args))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("RiskyPositionals(%q) = %#v, want %#v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// This test ensures that [RiskyPositionals] also checks the arguments
+// of the [RawRemainderToken] and the [AssignmentToken] values.
+func TestRiskyPositionalsRemainderAndAssignments(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:              []string{"-", "--"},
+		Separator:             "--",
+		CaptureRemainderAsRaw: true,
+		AssignmentChar:        "=",
+	}
+	args := []string{"NAME=x;id", "SAFE=1", "--", "sh", "-c", "a|b"}
+
+	got := RiskyPositionals(scanner.Scan(args))
+
+	expect := []PositionalArgumentToken{
+		{Idx: 0, Value: "NAME=x;id"},
+		{Idx: 5, Value: "a|b"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("RiskyPositionals(%q) = %#v, want %#v", args, got, expect)
+	}
+}
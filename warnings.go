@@ -0,0 +1,83 @@
+// warnings.go - Diagnosing confusing configurations and arguments.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrOptionLooksLikeSeparator indicates that an option renders
+// as the [Scanner.Separator] (e.g., an empty "--" long option).
+var ErrOptionLooksLikeSeparator = errors.New("option renders as the separator")
+
+// ErrOptionLooksLikeStdinMarker indicates that an option
+// renders as the [Scanner.StdinMarker].
+var ErrOptionLooksLikeStdinMarker = errors.New("option renders as the stdin marker")
+
+// ErrDeprecatedOption indicates that an option is listed
+// in the [Scanner.DeprecatedOptions].
+var ErrDeprecatedOption = errors.New("option is deprecated")
+
+// Validate checks the configuration for settings making options render as
+// the separator or the stdin marker, which is confusing for users. It returns
+// the [errors.Join] of [ErrOptionLooksLikeSeparator] when the separator may be
+// an option (i.e., with [Scanner.SeparatorOnlyAfterOptions]) and of
+// [ErrOptionLooksLikeStdinMarker] when the stdin marker starts with a prefix
+// followed by a name (e.g., "--stdin" with the "--" prefix), or nil.
+func (sx *Scanner) Validate() error {
+	var errs []error
+	if sx.SeparatorOnlyAfterOptions && sx.Separator != "" {
+		errs = append(errs, ErrOptionLooksLikeSeparator)
+	}
+	for _, prefix := range sx.Prefixes {
+		if len(sx.StdinMarker) > len(prefix) && strings.HasPrefix(sx.StdinMarker, prefix) {
+			errs = append(errs, ErrOptionLooksLikeStdinMarker)
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ScanWithWarnings is like [*Scanner.Scan] but also returns a [*ScanError]
+// warning about each suspicious argument, which does not prevent scanning
+// and therefore is not an error for [*Scanner.ScanStrict]. We warn about:
+//
+//  1. options rendering as the separator ([ErrOptionLooksLikeSeparator]);
+//
+//  2. options rendering as the stdin marker ([ErrOptionLooksLikeStdinMarker]);
+//
+//  3. options listed in [Scanner.DeprecatedOptions] ([ErrDeprecatedOption]),
+//     in which case the error message includes the suggested replacement.
+//
+// See also [*Scanner.Validate], which detects these issues in the configuration.
+//
+// This method does not mutate the [*Scanner] and is safe to call concurrently.
+func (sx *Scanner) ScanWithWarnings(args []string) ([]Token, []error) {
+	tokens := sx.Scan(args)
+	return tokens, sx.warnings(args, tokens)
+}
+
+// warnings implements [*Scanner.ScanWithWarnings] given the scanned tokens.
+func (sx *Scanner) warnings(args []string, tokens []Token) []error {
+	var warnings []error
+	for _, token := range tokens {
+		tk, ok := token.(OptionToken)
+		if !ok {
+			continue
+		}
+		switch form := tk.String(); {
+		case sx.Separator != "" && form == sx.Separator:
+			warnings = append(warnings, &ScanError{Idx: tk.Idx, Arg: args[tk.Idx], Err: ErrOptionLooksLikeSeparator})
+		case sx.StdinMarker != "" && form == sx.StdinMarker:
+			warnings = append(warnings, &ScanError{Idx: tk.Idx, Arg: args[tk.Idx], Err: ErrOptionLooksLikeStdinMarker})
+		}
+		if message, found := sx.DeprecatedOptions[tk.Name]; found {
+			err := fmt.Errorf("%w: %s", ErrDeprecatedOption, message)
+			warnings = append(warnings, &ScanError{Idx: tk.Idx, Arg: args[tk.Idx], Err: err})
+		}
+	}
+	return warnings
+}
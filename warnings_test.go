@@ -0,0 +1,117 @@
+// warnings_test.go - Tests for diagnosing confusing configurations and arguments.
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package flagscanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// This test ensures that [*Scanner.Validate] reports the settings
+// making options render as the separator or the stdin marker.
+func TestScannerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		scanner *Scanner
+		errs    []error
+	}{
+		{
+			name: "GNU style",
+			scanner: &Scanner{
+				Prefixes:    []string{"-", "--"},
+				Separator:   "--",
+				StdinMarker: "-",
+			},
+			errs: nil,
+		},
+		{
+			name: "separator only after options",
+			scanner: &Scanner{
+				Prefixes:                  []string{"-", "--"},
+				Separator:                 "--",
+				SeparatorOnlyAfterOptions: true,
+			},
+			errs: []error{ErrOptionLooksLikeSeparator},
+		},
+		{
+			name: "stdin marker starting with a prefix",
+			scanner: &Scanner{
+				Prefixes:    []string{"-", "--"},
+				StdinMarker: "--stdin",
+			},
+			errs: []error{ErrOptionLooksLikeStdinMarker},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scanner.Validate()
+			if (err == nil) != (len(tt.errs) == 0) {
+				t.Fatalf("Validate() = %v, want %v", err, tt.errs)
+			}
+			for _, expect := range tt.errs {
+				if !errors.Is(err, expect) {
+					t.Errorf("Validate() = %v, want %v", err, expect)
+				}
+			}
+		})
+	}
+}
+
+// This test ensures that [*Scanner.ScanWithWarnings] warns about options
+// rendering as the separator or the stdin marker.
+func TestScannerScanWithWarnings(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:                  []string{"-", "--"},
+		Separator:                 "--",
+		StdinMarker:               "--stdin",
+		SeparatorOnlyAfterOptions: true,
+	}
+	args := []string{"--", "--stdin", "-v"}
+
+	tokens, warnings := scanner.ScanWithWarnings(args)
+
+	if !reflect.DeepEqual(tokens, scanner.Scan(args)) {
+		t.Errorf("ScanWithWarnings(%q) = %#v, want the same tokens as Scan", args, tokens)
+	}
+	expect := []error{
+		&ScanError{Idx: 0, Arg: "--", Err: ErrOptionLooksLikeSeparator},
+		&ScanError{Idx: 1, Arg: "--stdin", Err: ErrOptionLooksLikeStdinMarker},
+	}
+	if !reflect.DeepEqual(warnings, expect) {
+		t.Errorf("ScanWithWarnings(%q) warnings = %v, want %v", args, warnings, expect)
+	}
+}
+
+// This test ensures that [*Scanner.ScanWithWarnings] warns about
+// deprecated options and suggests the replacement.
+func TestScannerScanWithWarningsDeprecatedOptions(t *testing.T) {
+	scanner := &Scanner{
+		Prefixes:          []string{"-", "--"},
+		Separator:         "--",
+		DeprecatedOptions: map[string]string{"out": "use --output instead"},
+	}
+
+	t.Run("deprecated option", func(t *testing.T) {
+		_, warnings := scanner.ScanWithWarnings([]string{"-v", "--out", "x"})
+		if len(warnings) != 1 {
+			t.Fatalf("ScanWithWarnings() warnings = %v, want one warning", warnings)
+		}
+		if !errors.Is(warnings[0], ErrDeprecatedOption) {
+			t.Errorf("ScanWithWarnings() warning = %v, want %v", warnings[0], ErrDeprecatedOption)
+		}
+		expect := `argument #1 ("--out"): option is deprecated: use --output instead`
+		if got := warnings[0].Error(); got != expect {
+			t.Errorf("ScanWithWarnings() warning = %q, want %q", got, expect)
+		}
+	})
+
+	t.Run("current option", func(t *testing.T) {
+		_, warnings := scanner.ScanWithWarnings([]string{"--output", "x", "--", "--out"})
+		if len(warnings) != 0 {
+			t.Errorf("ScanWithWarnings() warnings = %v, want none", warnings)
+		}
+	})
+}